@@ -0,0 +1,229 @@
+package errors
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+var (
+	ErrCircuitOpen = errors.New("breaker: circuit open")
+
+	DefaultBreakerCodes = []StatusCode{Unavailable, DeadlineExceeded, Internal}
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Breaker struct {
+	Parent    http.RoundTripper
+	Codes     []StatusCode
+	Threshold int
+	Cooldown  time.Duration
+	Key       func(req *http.Request) string
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+type circuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *Breaker) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	key := b.key(req)
+	if wait, ok := b.allow(key); !ok {
+		return nil, Annotate(ErrCircuitOpen,
+			Unavailable,
+			Message(key),
+			RetryInfo{RetryDelay: Duration(wait)},
+		)
+	}
+
+	resp, err = b.next(req)
+	b.record(key, err)
+	return
+}
+
+func (b *Breaker) State(key string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[key]; ok {
+		return c.state
+	}
+	return BreakerClosed
+}
+
+func (b *Breaker) States() map[string]BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make(map[string]BreakerState, len(b.circuits))
+	for key, c := range b.circuits {
+		out[key] = c.state
+	}
+	return out
+}
+
+func (b *Breaker) allow(key string) (wait time.Duration, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+	switch c.state {
+	case BreakerOpen:
+		wait = c.openedAt.Add(b.cooldown()).Sub(b.clock())
+		if wait > 0 {
+			return wait, false
+		}
+		c.state = BreakerHalfOpen
+		c.probing = true
+		return 0, true
+	case BreakerHalfOpen:
+		if c.probing {
+			return 0, false
+		}
+		c.probing = true
+		return 0, true
+	default:
+		return 0, true
+	}
+}
+
+func (b *Breaker) record(key string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+	c.probing = false
+
+	if Code(err) == Cancelled {
+		// The caller gave up, which says nothing about the upstream.
+		return
+	}
+	if !b.failure(err) {
+		c.state = BreakerClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == BreakerHalfOpen || c.failures >= b.threshold() {
+		c.state = BreakerOpen
+		c.openedAt = b.clock()
+	}
+}
+
+func (b *Breaker) failure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if isTransportError(err) {
+		return true
+	}
+
+	codes := b.Codes
+	if codes == nil {
+		codes = DefaultBreakerCodes
+	}
+
+	code := Code(err)
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransportError reports errors where no response was received, such as a
+// refused connection. They carry no status, so Code reports them as Unknown.
+func isTransportError(err error) bool {
+	var (
+		opErr  *net.OpError
+		dnsErr *net.DNSError
+	)
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return true
+	}
+	_, ok := ResponseInfo(err)
+	return !ok && Code(err) == Unknown
+}
+
+func (b *Breaker) circuit(key string) *circuit {
+	if b.circuits == nil {
+		b.circuits = make(map[string]*circuit)
+	}
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+func (b *Breaker) next(req *http.Request) (*http.Response, error) {
+	rt := b.Parent
+	if rt == nil {
+		rt = &RoundTripper{}
+	}
+	return rt.RoundTrip(req)
+}
+
+func (b *Breaker) key(req *http.Request) string {
+	if b.Key != nil {
+		return b.Key(req)
+	}
+	return req.URL.Host
+}
+
+func (b *Breaker) threshold() int {
+	if b.Threshold > 0 {
+		return b.Threshold
+	}
+	return DefaultBreakerThreshold
+}
+
+func (b *Breaker) cooldown() time.Duration {
+	if b.Cooldown > 0 {
+		return b.Cooldown
+	}
+	return DefaultBreakerCooldown
+}
+
+func (b *Breaker) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
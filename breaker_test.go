@@ -0,0 +1,192 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	var (
+		calls   int32
+		healthy int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":{"code":404,"message":"missing","status":"NOT_FOUND"}}`)
+		case atomic.LoadInt32(&healthy) == 0:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":{"code":503,"message":"down","status":"UNAVAILABLE"}}`)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	breaker := &Breaker{
+		Threshold: 2,
+		Cooldown:  time.Minute,
+		now:       func() time.Time { return now },
+	}
+	client := http.Client{Transport: breaker}
+	host := srv.Listener.Addr().String()
+
+	get := func(path string) error {
+		resp, err := client.Get(srv.URL + path)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	assert.Equal(t, NotFound, Code(get("/missing")))
+	assert.Equal(t, NotFound, Code(get("/missing")))
+	assert.Equal(t, BreakerClosed, breaker.State(host))
+
+	assert.Equal(t, Unavailable, Code(get("/")))
+	assert.Equal(t, BreakerClosed, breaker.State(host))
+	assert.Equal(t, Unavailable, Code(get("/")))
+	assert.Equal(t, BreakerOpen, breaker.State(host))
+	assert.Equal(t, map[string]BreakerState{host: BreakerOpen}, breaker.States())
+
+	atomic.StoreInt32(&calls, 0)
+	now = now.Add(10 * time.Second)
+
+	err := get("/")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, Unavailable, Code(err))
+	assert.Equal(t, []Any{RetryInfo{RetryDelay: Duration(50 * time.Second)}}, Details(err))
+	assert.Zero(t, atomic.LoadInt32(&calls))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, Unavailable, Code(get("/")))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	assert.Equal(t, BreakerOpen, breaker.State(host))
+
+	now = now.Add(time.Minute)
+	atomic.StoreInt32(&healthy, 1)
+	assert.NoError(t, get("/"))
+	assert.Equal(t, BreakerClosed, breaker.State(host))
+}
+
+func TestBreakerUpstreamFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bad-gateway":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = io.WriteString(w, "<html><title>Unavailable</title></html>")
+		case "/not-envelope":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":"backend down"}`)
+		case "/truncated":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":{"code":503,"message":"down","status":"UNAVAILABLE"}}`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, "<html><title>Unavailable</title></html>")
+		}
+	}))
+	defer srv.Close()
+
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	for name, target := range map[string]string{
+		"dead host": deadURL,
+		"html 502":  srv.URL + "/bad-gateway",
+		"html 503":  srv.URL + "/unavailable",
+		"json 503":  srv.URL + "/not-envelope",
+		"truncated": srv.URL + "/truncated",
+	} {
+		t.Run(name, func(t *testing.T) {
+			breaker := &Breaker{
+				Parent:    &RoundTripper{MaxBodySize: 32},
+				Threshold: 2,
+				Key:       func(*http.Request) string { return name },
+			}
+			client := http.Client{Transport: breaker}
+
+			for i := 0; i < 2; i++ {
+				_, err := client.Get(target)
+				assert.Error(t, err)
+			}
+			assert.Equal(t, BreakerOpen, breaker.State(name))
+
+			_, err := client.Get(target)
+			assert.ErrorIs(t, err, ErrCircuitOpen)
+		})
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := &Breaker{Threshold: 1}
+	b.record("a", Internal)
+	assert.Equal(t, BreakerOpen, b.State("a"))
+
+	b.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, ok := b.allow("a")
+	assert.True(t, ok)
+	assert.Equal(t, BreakerHalfOpen, b.State("a"))
+
+	_, ok = b.allow("a")
+	assert.False(t, ok)
+
+	b.record("a", &url.Error{Op: "Get", Err: NotFound})
+	assert.Equal(t, BreakerClosed, b.State("a"))
+}
+
+func TestBreakerCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	b := &Breaker{Threshold: 1}
+	host := srv.Listener.Addr().String()
+	b.record(host, Internal)
+
+	b.now = func() time.Time { return time.Now().Add(time.Hour) }
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	_, err := b.RoundTrip(req)
+	assert.Equal(t, Cancelled, Code(err))
+	assert.Equal(t, BreakerHalfOpen, b.State(host))
+	assert.Equal(t, 1, b.circuits[host].failures)
+
+	_, ok := b.allow(host)
+	assert.True(t, ok)
+}
+
+func TestBreakerState(t *testing.T) {
+	for state, name := range map[BreakerState]string{
+		BreakerClosed:   "closed",
+		BreakerOpen:     "open",
+		BreakerHalfOpen: "half-open",
+		-1:              "unknown",
+	} {
+		assert.Equal(t, name, state.String())
+	}
+}
@@ -0,0 +1,218 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gota33/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	reasonPattern      = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+type Catalog struct {
+	Package string  `yaml:"package" json:"package"`
+	Domain  string  `yaml:"domain" json:"domain"`
+	Errors  []Entry `yaml:"errors" json:"errors"`
+}
+
+type Entry struct {
+	Reason      string            `yaml:"reason" json:"reason"`
+	Name        string            `yaml:"name" json:"name"`
+	Status      errors.StatusName `yaml:"status" json:"status"`
+	Message     string            `yaml:"message" json:"message"`
+	Description string            `yaml:"description" json:"description"`
+	Metadata    []string          `yaml:"metadata" json:"metadata"`
+	Localized   map[string]string `yaml:"localized" json:"localized"`
+	Help        []Link            `yaml:"help" json:"help"`
+	Routes      []string          `yaml:"routes" json:"routes"`
+}
+
+type Link struct {
+	Description string `yaml:"description" json:"description"`
+	Url         string `yaml:"url" json:"url"`
+}
+
+type Segment struct {
+	Text string
+	Key  string
+}
+
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func Parse(data []byte) (*Catalog, error) {
+	var c Catalog
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *Catalog) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("catalog: "+format, args...))
+	}
+
+	if c.Domain == "" {
+		fail("domain is required")
+	}
+	if c.Package != "" && !token.IsIdentifier(c.Package) {
+		fail("invalid package name %q", c.Package)
+	}
+
+	reasons := make(map[string]bool, len(c.Errors))
+	names := make(map[string]string, len(c.Errors))
+	for i, e := range c.Errors {
+		if !reasonPattern.MatchString(e.Reason) {
+			fail("errors[%d]: invalid reason %q", i, e.Reason)
+			continue
+		}
+		if reasons[e.Reason] {
+			fail("%s: duplicate reason", e.Reason)
+		}
+		reasons[e.Reason] = true
+
+		if name := e.GoName(); !token.IsIdentifier(name) || !token.IsExported(name) {
+			fail("%s: invalid name %q", e.Reason, name)
+		} else if prev, ok := names[name]; ok {
+			fail("%s: name %q already used by %s", e.Reason, name, prev)
+		} else {
+			names[name] = e.Reason
+		}
+
+		if code := e.Code(); code == errors.OK || code.String() != e.Status.String() {
+			fail("%s: invalid status %q", e.Reason, e.Status)
+		}
+		if e.Message == "" {
+			fail("%s: message is required", e.Reason)
+		}
+
+		keys := make(map[string]bool, len(e.Metadata))
+		for _, key := range e.Metadata {
+			if !token.IsIdentifier(key) {
+				fail("%s: invalid metadata key %q", e.Reason, key)
+			}
+			if keys[key] {
+				fail("%s: duplicate metadata key %q", e.Reason, key)
+			}
+			keys[key] = true
+		}
+
+		templates := map[string]string{"message": e.Message}
+		for local, msg := range e.Localized {
+			templates["localized."+local] = msg
+		}
+		for field, tmpl := range templates {
+			for _, key := range Placeholders(tmpl) {
+				if !keys[key] {
+					fail("%s: %s references undeclared metadata key %q", e.Reason, field, key)
+				}
+			}
+		}
+
+		for j, link := range e.Help {
+			if link.Url == "" {
+				fail("%s: help[%d]: url is required", e.Reason, j)
+			}
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+func (e Entry) GoName() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return camel(e.Reason)
+}
+
+func (e Entry) Sentinel() string {
+	return "Err" + e.GoName()
+}
+
+func (e Entry) Code() errors.StatusCode {
+	return e.Status.StatusCode()
+}
+
+func (e Entry) Locales() []string {
+	locales := make([]string, 0, len(e.Localized))
+	for local := range e.Localized {
+		locales = append(locales, local)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func ParseTemplate(tmpl string) (out []Segment) {
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(tmpl, -1) {
+		if m[0] > last {
+			out = append(out, Segment{Text: tmpl[last:m[0]]})
+		}
+		out = append(out, Segment{Key: tmpl[m[2]:m[3]]})
+		last = m[1]
+	}
+	if last < len(tmpl) {
+		out = append(out, Segment{Text: tmpl[last:]})
+	}
+	return
+}
+
+func Placeholders(tmpl string) (keys []string) {
+	for _, s := range ParseTemplate(tmpl) {
+		if s.Key != "" {
+			keys = append(keys, s.Key)
+		}
+	}
+	return
+}
+
+func Render(tmpl string, values map[string]string) string {
+	var sb strings.Builder
+	for _, s := range ParseTemplate(tmpl) {
+		if s.Key == "" {
+			sb.WriteString(s.Text)
+		} else if v, ok := values[s.Key]; ok {
+			sb.WriteString(v)
+		} else {
+			sb.WriteString("{" + s.Key + "}")
+		}
+	}
+	return sb.String()
+}
+
+func camel(s string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(strings.ToLower(s), "_") {
+		if part != "" {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return sb.String()
+}
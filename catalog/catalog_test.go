@@ -0,0 +1,101 @@
+package catalog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gota33/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	c, err := Load("testdata/pets.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "pet.example.com", c.Domain)
+	if assert.Len(t, c.Errors, 3) {
+		e := c.Errors[0]
+		assert.Equal(t, "CatNotFound", e.GoName())
+		assert.Equal(t, "ErrCatNotFound", e.Sentinel())
+		assert.Equal(t, errors.NotFound, e.Code())
+		assert.Equal(t, []string{"en-US", "zh-CN"}, e.Locales())
+		assert.Equal(t, "StoreClosed", c.Errors[2].GoName())
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	c, err := Parse([]byte(`{"domain": "pet.example.com", "errors": [{"reason": "CAT_NOT_FOUND", "status": "not_found", "message": "cat {name} not found", "metadata": ["name"]}]}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, errors.NotFound, c.Errors[0].Code())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	_, err := Parse([]byte(`
+package: 1pets
+errors:
+  - reason: cat-not-found
+  - reason: CAT_NOT_FOUND
+    status: MISSING
+    message: "cat {name} not found"
+    localized:
+      en: "{id}"
+    help: [{description: docs}]
+  - reason: CAT_NOT_FOUND
+    status: OK
+`))
+	if assert.Error(t, err) {
+		for _, msg := range []string{
+			`domain is required`,
+			`invalid package name "1pets"`,
+			`errors[0]: invalid reason "cat-not-found"`,
+			`CAT_NOT_FOUND: invalid status "MISSING"`,
+			`CAT_NOT_FOUND: message references undeclared metadata key "name"`,
+			`CAT_NOT_FOUND: localized.en references undeclared metadata key "id"`,
+			`CAT_NOT_FOUND: help[0]: url is required`,
+			`CAT_NOT_FOUND: duplicate reason`,
+			`CAT_NOT_FOUND: invalid status "OK"`,
+			`CAT_NOT_FOUND: message is required`,
+		} {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+
+	_, err = Parse([]byte("domain: a\nunknown: 1\n"))
+	assert.ErrorContains(t, err, "field unknown not found")
+}
+
+func TestTemplate(t *testing.T) {
+	assert.Equal(t, []Segment{{Text: "cat "}, {Key: "name"}, {Text: " {not a key}"}}, ParseTemplate("cat {name} {not a key}"))
+	assert.Equal(t, []string{"owner_id", "limit"}, Placeholders("{owner_id} has {limit}"))
+	assert.Equal(t, "cat tom owned by {owner}", Render("cat {name} owned by {owner}", map[string]string{"name": "tom"}))
+	assert.Equal(t, `"a " + type_ + " b"`, goExpr("a {type} b"))
+	assert.Equal(t, "type_", goParam("type"))
+	assert.Equal(t, "ownerId", goParam("owner_id"))
+}
+
+func TestGenerated(t *testing.T) {
+	c, err := Load("testdata/pets.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var code, doc bytes.Buffer
+	assert.NoError(t, c.WriteGo(&code, "pets.yaml"))
+	assert.NoError(t, c.WriteMarkdown(&doc, "pets.yaml"))
+
+	dir := filepath.Join("internal", "petcatalog")
+	for name, actual := range map[string][]byte{"pets_errors.go": code.Bytes(), "pets_errors.md": doc.Bytes()} {
+		expected, err := os.ReadFile(filepath.Join(dir, name))
+		if assert.NoError(t, err) {
+			assert.Equal(t, string(expected), string(actual), "%s is stale, run go generate ./catalog/...", name)
+		}
+	}
+
+	c.Package = ""
+	assert.Error(t, c.WriteGo(&code, "pets.yaml"))
+}
@@ -0,0 +1,129 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{
+	"quote":  strconv.Quote,
+	"expr":   goExpr,
+	"param":  goParam,
+	"params": goParams,
+	"code":   func(e Entry) string { return camel(e.Code().String()) },
+	"doc":    func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}).Parse(`// Code generated by errgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/gota33/errors"
+
+const Domain = {{quote .Domain}}
+
+var (
+{{- range .Errors}}
+	{{.Sentinel}} = errors.New({{quote (printf "%s: %s" $.Domain .Reason)}})
+{{- end}}
+)
+
+type catalogError struct {
+	sentinel error
+	message  string
+}
+
+func (e catalogError) Error() string { return e.message }
+func (e catalogError) Unwrap() error { return e.sentinel }
+{{range .Errors}}
+{{- if .Description}}
+// {{.GoName}} returns a {{.Reason}} error. {{doc .Description}}
+{{- end}}
+func {{.GoName}}({{params .}}) error {
+	return errors.Annotate(catalogError{ {{- .Sentinel}}, {{expr .Message}}},
+		errors.{{code .}},
+		errors.ErrorInfo{Reason: {{quote .Reason}}, Domain: Domain
+		{{- if .Metadata}}, Metadata: map[string]string{
+		{{- range .Metadata}}
+			{{quote .}}: {{param .}},
+		{{- end}}
+		}{{end}}},
+	{{- $entry := .}}
+	{{- range .Locales}}
+		errors.LocalizedMessage{Local: {{quote .}}, Message: {{expr (index $entry.Localized .)}}},
+	{{- end}}
+	{{- if .Help}}
+		errors.Help{Links: []errors.Link{
+		{{- range .Help}}
+			{Description: {{quote .Description}}, Url: {{quote .Url}}},
+		{{- end}}
+		}},
+	{{- end}}
+	)
+}
+{{end}}`))
+
+func (c *Catalog) WriteGo(w io.Writer, source string) error {
+	if c.Package == "" {
+		return fmt.Errorf("catalog: package is required to generate Go code")
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		*Catalog
+		Source string
+	}{c, source}
+	if err := goTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("catalog: format generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func goParam(key string) string {
+	name := camel(key)
+	if name == "" {
+		name = "v"
+	}
+	name = strings.ToLower(name[:1]) + name[1:]
+	if token.IsKeyword(name) || name == "errors" {
+		name += "_"
+	}
+	return name
+}
+
+func goParams(e Entry) string {
+	if len(e.Metadata) == 0 {
+		return ""
+	}
+	params := make([]string, len(e.Metadata))
+	for i, key := range e.Metadata {
+		params[i] = goParam(key)
+	}
+	return strings.Join(params, ", ") + " string"
+}
+
+func goExpr(tmpl string) string {
+	segments := ParseTemplate(tmpl)
+	if len(segments) == 0 {
+		return `""`
+	}
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		if s.Key != "" {
+			parts[i] = goParam(s.Key)
+		} else {
+			parts[i] = strconv.Quote(s.Text)
+		}
+	}
+	return strings.Join(parts, " + ")
+}
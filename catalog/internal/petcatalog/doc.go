@@ -0,0 +1,3 @@
+package petcatalog
+
+//go:generate go run ../../../cmd/errgen -in ../../testdata/pets.yaml -out pets_errors.go -doc pets_errors.md
@@ -0,0 +1,53 @@
+// Code generated by errgen from pets.yaml. DO NOT EDIT.
+
+package petcatalog
+
+import "github.com/gota33/errors"
+
+const Domain = "pet.example.com"
+
+var (
+	ErrCatNotFound      = errors.New("pet.example.com: CAT_NOT_FOUND")
+	ErrCatQuotaExceeded = errors.New("pet.example.com: CAT_QUOTA_EXCEEDED")
+	ErrStoreClosed      = errors.New("pet.example.com: PET_STORE_CLOSED")
+)
+
+type catalogError struct {
+	sentinel error
+	message  string
+}
+
+func (e catalogError) Error() string { return e.message }
+func (e catalogError) Unwrap() error { return e.sentinel }
+
+// CatNotFound returns a CAT_NOT_FOUND error. The requested cat does not exist or was adopted.
+func CatNotFound(name string) error {
+	return errors.Annotate(catalogError{ErrCatNotFound, "cat " + name + " not found"},
+		errors.NotFound,
+		errors.ErrorInfo{Reason: "CAT_NOT_FOUND", Domain: Domain, Metadata: map[string]string{
+			"name": name,
+		}},
+		errors.LocalizedMessage{Local: "en-US", Message: "Cat " + name + " not found"},
+		errors.LocalizedMessage{Local: "zh-CN", Message: "找不到猫 " + name},
+		errors.Help{Links: []errors.Link{
+			{Description: "Cat API reference", Url: "https://pet.example.com/docs/cats"},
+		}},
+	)
+}
+
+func CatQuotaExceeded(ownerId, limit string) error {
+	return errors.Annotate(catalogError{ErrCatQuotaExceeded, "owner " + ownerId + " already has " + limit + " cats"},
+		errors.ResourceExhausted,
+		errors.ErrorInfo{Reason: "CAT_QUOTA_EXCEEDED", Domain: Domain, Metadata: map[string]string{
+			"owner_id": ownerId,
+			"limit":    limit,
+		}},
+	)
+}
+
+func StoreClosed() error {
+	return errors.Annotate(catalogError{ErrStoreClosed, "pet store is closed"},
+		errors.Unavailable,
+		errors.ErrorInfo{Reason: "PET_STORE_CLOSED", Domain: Domain},
+	)
+}
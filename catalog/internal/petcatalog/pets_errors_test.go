@@ -0,0 +1,37 @@
+package petcatalog
+
+import (
+	"testing"
+
+	"github.com/gota33/errors"
+	"github.com/gota33/errors/errorstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatNotFound(t *testing.T) {
+	err := CatNotFound("tom")
+
+	assert.EqualError(t, err, "cat tom not found")
+	assert.ErrorIs(t, err, ErrCatNotFound)
+	assert.NotErrorIs(t, err, ErrStoreClosed)
+	errorstest.AssertCode(t, err, errors.NotFound)
+	errorstest.AssertReason(t, err, Domain, "CAT_NOT_FOUND")
+
+	info, _ := errorstest.AssertDetail[errors.ErrorInfo](t, err)
+	assert.Equal(t, map[string]string{"name": "tom"}, info.Metadata)
+	msg, _ := errorstest.AssertDetail[errors.LocalizedMessage](t, err)
+	assert.Equal(t, errors.LocalizedMessage{Local: "en-US", Message: "Cat tom not found"}, msg)
+	errorstest.AssertDetail[errors.Help](t, err)
+}
+
+func TestCatQuotaExceeded(t *testing.T) {
+	err := CatQuotaExceeded("u1", "3")
+
+	assert.EqualError(t, err, "owner u1 already has 3 cats")
+	assert.ErrorIs(t, err, ErrCatQuotaExceeded)
+	errorstest.AssertCode(t, err, errors.ResourceExhausted)
+
+	wrapped := errors.Annotate(err, errors.Message("adopt"))
+	assert.ErrorIs(t, wrapped, ErrCatQuotaExceeded)
+	errorstest.AssertCode(t, wrapped, errors.ResourceExhausted)
+}
@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+var markdownTemplate = template.Must(template.New("md").Funcs(template.FuncMap{
+	"http": func(e Entry) int { return e.Code().Http() },
+	"cell": func(s string) string { return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", `\|`) },
+	"anchor": func(reason string) string {
+		return strings.ToLower(reason)
+	},
+	"join": func(items []string) string {
+		return "`" + strings.Join(items, "`, `") + "`"
+	},
+}).Parse(`<!-- Code generated by errgen from {{.Source}}. DO NOT EDIT. -->
+
+# {{.Domain}} errors
+
+| Reason | Status | HTTP | Message |
+| ------ | ------ | ---- | ------- |
+{{- range .Errors}}
+| [{{.Reason}}](#{{anchor .Reason}}) | ` + "`{{.Status}}`" + ` | {{http .}} | {{cell .Message}} |
+{{- end}}
+{{range .Errors}}
+## {{.Reason}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+- Status: ` + "`{{.Status}}`" + ` ({{http .}})
+- Message: ` + "`{{.Message}}`" + `
+{{- if .Metadata}}
+- Metadata: {{join .Metadata}}
+{{- end}}
+{{- if .Routes}}
+- Routes: {{join .Routes}}
+{{- end}}
+{{- $entry := .}}
+{{- if .Localized}}
+- Localized:
+{{- range .Locales}}
+  - {{.}}: {{index $entry.Localized .}}
+{{- end}}
+{{- end}}
+{{- if .Help}}
+- Help:
+{{- range .Help}}
+  - [{{if .Description}}{{.Description}}{{else}}{{.Url}}{{end}}]({{.Url}})
+{{- end}}
+{{- end}}
+{{end}}`))
+
+func (c *Catalog) WriteMarkdown(w io.Writer, source string) error {
+	data := struct {
+		*Catalog
+		Source string
+	}{c, source}
+	if err := markdownTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("catalog: render markdown: %w", err)
+	}
+	return nil
+}
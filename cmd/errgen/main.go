@@ -0,0 +1,70 @@
+// Command errgen generates typed error constructors and Markdown reference
+// docs from an error catalog file.
+//
+//	errgen -in errors.yaml -out errors_gen.go -doc ERRORS.md
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gota33/errors/catalog"
+)
+
+func main() {
+	var (
+		in      = flag.String("in", "", "catalog file (YAML or JSON)")
+		out     = flag.String("out", "", "generated Go file (default stdout)")
+		doc     = flag.String("doc", "", "generated Markdown file")
+		pkgName = flag.String("package", "", "Go package name (overrides the catalog)")
+	)
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*in, *out, *doc, *pkgName); err != nil {
+		fmt.Fprintln(os.Stderr, "errgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, doc, pkgName string) error {
+	c, err := catalog.Load(in)
+	if err != nil {
+		return err
+	}
+	if pkgName != "" {
+		c.Package = pkgName
+	}
+
+	source := filepath.Base(in)
+	if out != "" || doc == "" {
+		if err = write(out, func(w io.Writer) error { return c.WriteGo(w, source) }); err != nil {
+			return err
+		}
+	}
+	if doc != "" {
+		if err = write(doc, func(w io.Writer) error { return c.WriteMarkdown(w, source) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func write(path string, render func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	if path == "" {
+		_, err := buf.WriteTo(os.Stdout)
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
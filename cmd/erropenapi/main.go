@@ -0,0 +1,78 @@
+// Command erropenapi writes OpenAPI 3.1 components for the error envelope,
+// every registered detail type and the error responses of catalog routes.
+//
+//	erropenapi -catalog pets.yaml -format yaml -out errors.openapi.yaml
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gota33/errors/catalog"
+	"github.com/gota33/errors/openapi"
+	"gopkg.in/yaml.v3"
+)
+
+func main() {
+	var (
+		catalogs []string
+		out      = flag.String("out", "", "output file (default stdout)")
+		format   = flag.String("format", "json", "output format: json or yaml")
+		title    = flag.String("title", openapi.DefaultTitle, "info.title of the document")
+		version  = flag.String("version", openapi.DefaultVersion, "info.version of the document")
+	)
+	flag.Func("catalog", "catalog file (YAML or JSON), may be repeated", func(s string) error {
+		catalogs = append(catalogs, s)
+		return nil
+	})
+	flag.Parse()
+
+	g := openapi.Generator{Title: *title, Version: *version}
+	if err := run(&g, catalogs, *format, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "erropenapi:", err)
+		os.Exit(1)
+	}
+}
+
+func run(g *openapi.Generator, catalogs []string, format, out string) error {
+	for _, path := range catalogs {
+		c, err := catalog.Load(path)
+		if err != nil {
+			return err
+		}
+		g.Catalogs = append(g.Catalogs, c)
+	}
+
+	doc, err := g.Generate()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		data = append(data, '\n')
+	case "yaml":
+		var v interface{}
+		if err = json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		if data, err = yaml.Marshal(v); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	errs "github.com/gota33/errors"
+)
+
+func diff(a, b error) (lines []string) {
+	if ca, cb := errs.Code(a), errs.Code(b); ca != cb {
+		lines = append(lines, fmt.Sprintf("status: %s -> %s", ca.String(), cb.String()))
+	}
+	if a.Error() != b.Error() {
+		lines = append(lines, fmt.Sprintf("message: %q -> %q", a.Error(), b.Error()))
+	}
+	if ra, rb := reasonOf(a), reasonOf(b); ra != rb {
+		lines = append(lines, fmt.Sprintf("reason: %s -> %s", ra, rb))
+	}
+
+	da, db := detailsByType(a), detailsByType(b)
+	types := make([]string, 0, len(da)+len(db))
+	for typeUrl := range da {
+		types = append(types, typeUrl)
+	}
+	for typeUrl := range db {
+		if _, ok := da[typeUrl]; !ok {
+			types = append(types, typeUrl)
+		}
+	}
+	sort.Strings(types)
+
+	for _, typeUrl := range types {
+		removed, added := subtract(da[typeUrl], db[typeUrl]), subtract(db[typeUrl], da[typeUrl])
+		for _, d := range removed {
+			lines = append(lines, fmt.Sprintf("- %s %s", typeUrl, d))
+		}
+		for _, d := range added {
+			lines = append(lines, fmt.Sprintf("+ %s %s", typeUrl, d))
+		}
+	}
+	return
+}
+
+func reasonOf(err error) string {
+	for _, d := range errs.Details(err) {
+		if info, ok := d.(errs.ErrorInfo); ok {
+			return info.Domain + "/" + info.Reason
+		}
+	}
+	return "(none)"
+}
+
+func detailsByType(err error) map[string][]string {
+	out := make(map[string][]string)
+	for _, d := range errs.Details(err) {
+		data, mErr := json.Marshal(d)
+		if mErr != nil {
+			data = []byte(fmt.Sprintf("%q", mErr.Error()))
+		}
+		out[d.TypeUrl()] = append(out[d.TypeUrl()], string(data))
+	}
+	return out
+}
+
+func subtract(a, b []string) (out []string) {
+	count := make(map[string]int, len(b))
+	for _, s := range b {
+		count[s]++
+	}
+	for _, s := range a {
+		if count[s] > 0 {
+			count[s]--
+			continue
+		}
+		out = append(out, s)
+	}
+	return
+}
@@ -0,0 +1,149 @@
+// Command errs decodes, pretty-prints, converts and diffs Google-style error
+// payloads such as the ones found in logs.
+//
+//	errs [-format text|json|problem] [file]
+//	errs diff a.json b.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	errs "github.com/gota33/errors"
+)
+
+var errDiffer = errors.New("payloads differ")
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	switch {
+	case err == nil:
+	case errors.Is(err, errDiffer):
+		os.Exit(1)
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "errs:", err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) > 0 && args[0] == "diff" {
+		return runDiff(args[1:], stdout)
+	}
+
+	fs := flag.NewFlagSet("errs", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format: text, json or problem")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+
+	in, err := load(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		text := fmt.Sprintf("%+v", in)
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		_, err = io.WriteString(stdout, text)
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = errs.NewEncoder(enc).Encode(in)
+	case "problem":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(problemOf(in))
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	return err
+}
+
+func runDiff(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: errs diff a.json b.json")
+	}
+
+	a, err := load(args[0], nil)
+	if err != nil {
+		return err
+	}
+	b, err := load(args[1], nil)
+	if err != nil {
+		return err
+	}
+
+	lines := diff(a, b)
+	for _, line := range lines {
+		if _, err = fmt.Fprintln(stdout, line); err != nil {
+			return err
+		}
+	}
+	if len(lines) > 0 {
+		return errDiffer
+	}
+	return nil
+}
+
+func load(path string, stdin io.Reader) (error, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "" || path == "-" {
+		if stdin == nil {
+			return nil, fmt.Errorf("stdin can only be read once")
+		}
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// decode accepts an error envelope, possibly prefixed by log noise, or a bare
+// status object without the "error" wrapper.
+func decode(data []byte) (error, error) {
+	if i := bytes.IndexByte(data, '{'); i > 0 {
+		data = data[i:]
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&probe); err != nil {
+		return nil, fmt.Errorf("parse payload: %w", err)
+	}
+	if _, ok := probe["error"]; !ok {
+		wrapped, err := json.Marshal(map[string]interface{}{"error": probe})
+		if err != nil {
+			return nil, err
+		}
+		data = wrapped
+	}
+
+	dec := errs.NewDecoder(json.NewDecoder(bytes.NewReader(data)))
+	dec.Mode = errs.DecodeLenient
+
+	err := dec.Decode()
+	if _, ok := errors.Unwrap(err).(errs.StatusCode); !ok {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	return err, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const payload = `{"error":{"code":404,"message":"cat not found","status":"NOT_FOUND","details":[
+	{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"CAT_NOT_FOUND","domain":"pet.com"},
+	{"@type":"type.googleapis.com/google.rpc.ResourceInfo","resourceName":"cats/tom"},
+	{"@type":"type.googleapis.com/google.rpc.Help","links":[{"url":"https://pet.com/docs"}]}
+]}}`
+
+func runWith(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &out)
+	return out.String(), err
+}
+
+func TestText(t *testing.T) {
+	out, err := runWith(t, `time=10:00 level=error body=`+payload)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "status: \"404 NOT_FOUND\"\nmessage: \"cat not found\"\n"), out)
+	assert.Contains(t, out, `reason: "CAT_NOT_FOUND"`)
+	assert.False(t, strings.HasSuffix(out, "\n\n"))
+
+	out, err = runWith(t, `{"code":503,"message":"busy","status":"UNAVAILABLE"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "status: \"503 UNAVAILABLE\"\nmessage: \"busy\"\n", out)
+}
+
+func TestJSON(t *testing.T) {
+	out, err := runWith(t, payload, "-format", "json")
+	assert.NoError(t, err)
+	assert.JSONEq(t, payload, out)
+}
+
+func TestProblem(t *testing.T) {
+	out, err := runWith(t, payload, "--format", "problem")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "https://pet.com/docs",
+		"title": "Not Found",
+		"status": 404,
+		"detail": "cat not found",
+		"instance": "cats/tom",
+		"code": "NOT_FOUND",
+		"reason": "CAT_NOT_FOUND",
+		"domain": "pet.com",
+		"details": [
+			{"@type": "type.googleapis.com/google.rpc.ResourceInfo", "resourceName": "cats/tom"},
+			{"@type": "type.googleapis.com/google.rpc.Help", "links": [{"url": "https://pet.com/docs"}]}
+		]
+	}`, out)
+}
+
+func TestInvalid(t *testing.T) {
+	_, err := runWith(t, payload, "-format", "yaml")
+	assert.EqualError(t, err, `unknown format "yaml"`)
+
+	_, err = runWith(t, "not json")
+	assert.ErrorContains(t, err, "parse payload")
+
+	_, err = runWith(t, `{"error":{"status":"NOT_FOUND","details":{}}}`)
+	assert.ErrorContains(t, err, "decode payload")
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+		return path
+	}
+
+	a := write("a.json", payload)
+	b := write("b.json", `{"error":{"code":500,"message":"cat not found","status":"INTERNAL","details":[
+		{"@type":"type.googleapis.com/google.rpc.Help","links":[{"url":"https://pet.com/docs"}]},
+		{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"CAT_GONE","domain":"pet.com"},
+		{"@type":"type.googleapis.com/google.rpc.RequestInfo","requestId":"r1"}
+	]}}`)
+	c := write("c.json", strings.ReplaceAll(payload, "\n", " "))
+
+	out, err := runWith(t, "", "diff", a, b)
+	assert.ErrorIs(t, err, errDiffer)
+	assert.Equal(t, strings.Join([]string{
+		`status: NOT_FOUND -> INTERNAL`,
+		`reason: pet.com/CAT_NOT_FOUND -> pet.com/CAT_GONE`,
+		`- type.googleapis.com/google.rpc.ErrorInfo {"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"CAT_NOT_FOUND","domain":"pet.com"}`,
+		`+ type.googleapis.com/google.rpc.ErrorInfo {"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"CAT_GONE","domain":"pet.com"}`,
+		`+ type.googleapis.com/google.rpc.RequestInfo {"@type":"type.googleapis.com/google.rpc.RequestInfo","requestId":"r1"}`,
+		`- type.googleapis.com/google.rpc.ResourceInfo {"@type":"type.googleapis.com/google.rpc.ResourceInfo","resourceName":"cats/tom"}`,
+		``,
+	}, "\n"), out)
+
+	out, err = runWith(t, "", "diff", a, c)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = runWith(t, "", "diff", a)
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+
+	errs "github.com/gota33/errors"
+)
+
+// problem is an RFC 9457 problem details object. Error metadata that has no
+// standard member is carried as extension members.
+type problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Reason   string            `json:"reason,omitempty"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Details  []errs.Any        `json:"details,omitempty"`
+}
+
+func problemOf(err error) problem {
+	code := errs.Code(err)
+	p := problem{
+		Type:   "about:blank",
+		Status: code.Http(),
+		Title:  http.StatusText(code.Http()),
+		Detail: err.Error(),
+		Code:   code.String(),
+	}
+
+	for _, d := range errs.Details(err) {
+		switch d := d.(type) {
+		case errs.ErrorInfo:
+			p.Reason, p.Domain, p.Metadata = d.Reason, d.Domain, d.Metadata
+			continue
+		case errs.Help:
+			if len(d.Links) > 0 && p.Type == "about:blank" {
+				p.Type = d.Links[0].Url
+			}
+		case errs.ResourceInfo:
+			if p.Instance == "" {
+				p.Instance = d.ResourceName
+			}
+		}
+		p.Details = append(p.Details, d)
+	}
+	return p
+}
@@ -1,36 +1,20 @@
 package errors
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
 )
 
 var (
-	ErrNoEncoder = errors.New("encoder: inner encoder is required")
-	ErrNoDecoder = errors.New("decoder: inner decoder is required")
+	ErrNoEncoder   = errors.New("encoder: inner encoder is required")
+	ErrNoDecoder   = errors.New("decoder: inner decoder is required")
+	ErrUnknownType = errors.New("decoder: unknown detail type")
 )
 
-var typeProvider = map[string]func() Any{
-	TypeUrlRetryInfo:           func() Any { return new(RetryInfo) },
-	TypeUrlDebugInfo:           func() Any { return new(DebugInfo) },
-	TypeUrlResourceInfo:        func() Any { return new(ResourceInfo) },
-	TypeUrlBadRequest:          func() Any { return new(BadRequest) },
-	TypeUrlPreconditionFailure: func() Any { return new(PreconditionFailure) },
-	TypeUrlErrorInfo:           func() Any { return new(ErrorInfo) },
-	TypeUrlQuotaFailure:        func() Any { return new(QuotaFailure) },
-	TypeUrlRequestInfo:         func() Any { return new(RequestInfo) },
-	TypeUrlHelp:                func() Any { return new(Help) },
-	TypeUrlLocalizedMessage:    func() Any { return new(LocalizedMessage) },
-}
-
-func Register(typeUrl string, provider func() Any) {
-	if provider == nil {
-		delete(typeProvider, typeUrl)
-	} else {
-		typeProvider[typeUrl] = provider
-	}
-}
-
 type message struct {
 	Error messageBody `json:"error,omitempty"`
 }
@@ -48,6 +32,8 @@ type encoder interface {
 
 type Encoder struct {
 	Mappers []DetailMapper
+	Mapping HttpMapping
+	Tracer  Tracer
 	encoder
 }
 
@@ -56,9 +42,13 @@ func NewEncoder(enc encoder) *Encoder {
 }
 
 func (e *Encoder) Encode(in error) error {
+	return e.EncodeContext(context.Background(), in)
+}
+
+func (e *Encoder) EncodeContext(ctx context.Context, in error) error {
 	var body messageBody
 	if a, ok := Flatten(in, e.Mappers...).(*annotated); ok {
-		body.Code = a.code.Http()
+		body.Code = e.Mapping.Http(a.code)
 		body.Status = a.code.Name()
 		body.Message = a.message
 		body.Details = a.details
@@ -68,6 +58,9 @@ func (e *Encoder) Encode(in error) error {
 		return ErrNoEncoder
 	}
 
+	if e.Tracer != nil {
+		e.Tracer.TraceError(ctx, newTrace(in, body.Code, e.Mappers))
+	}
 	return e.encoder.Encode(message{body})
 }
 
@@ -86,12 +79,38 @@ type decoder interface {
 	Decode(v interface{}) error
 }
 
+type DecodeMode int
+
+const (
+	DecodeDefault DecodeMode = iota
+	DecodeStrict
+	DecodeLenient
+)
+
+type DetailError struct {
+	Index int
+	Type  string
+	Err   error
+}
+
+func (e *DetailError) Error() string {
+	return fmt.Sprintf("decoder: detail[%d] %q: %s", e.Index, e.Type, e.Err)
+}
+
+func (e *DetailError) Unwrap() error { return e.Err }
+
 type Decoder struct {
-	dec decoder
+	Mode     DecodeMode
+	Registry *Registry
+	dec      decoder
 }
 
-func NewDecoder(dec decoder) *Decoder {
-	return &Decoder{dec: dec}
+func NewDecoder(dec decoder, registry ...*Registry) *Decoder {
+	d := &Decoder{dec: dec}
+	if len(registry) > 0 {
+		d.Registry = registry[0]
+	}
+	return d
 }
 
 func (d Decoder) Decode() (err error) {
@@ -101,10 +120,10 @@ func (d Decoder) Decode() (err error) {
 
 	var msg encodedMessage
 	if err = d.dec.Decode(&msg); err != nil {
-		return
+		return Annotate(err, DataLoss)
 	}
 
-	details, err := decodeDetails(msg.Error.Details)
+	details, err := d.decodeDetails(msg.Error.Details)
 	if err != nil {
 		return
 	}
@@ -120,37 +139,85 @@ func (d Decoder) Decode() (err error) {
 	}
 }
 
-func decodeDetails(raw []byte) (details []Any, err error) {
+func (d Decoder) decodeDetails(raw []byte) (details []Any, err error) {
 	if raw == nil {
 		return []Any{}, nil
 	}
 	var wrappers []json.RawMessage
 	if err = json.Unmarshal(raw, &wrappers); err != nil {
-		return
+		return nil, Annotate(err, DataLoss, Message("decoder: details"))
 	}
 
-	details = make([]Any, len(wrappers))
+	details = make([]Any, 0, len(wrappers))
 	for i, wrapper := range wrappers {
-		if details[i], err = decodeDetail(wrapper); err != nil {
-			return
+		detail, dErr := d.decodeDetail(wrapper)
+		if dErr != nil {
+			dErr.Index = i
+			if d.Mode != DecodeLenient {
+				return nil, Annotate(dErr, DataLoss)
+			}
+			detail = RawDetail{Type: dErr.Type, Raw: wrapper, Err: dErr.Err}
 		}
+		details = append(details, detail)
 	}
 	return
 }
 
-func decodeDetail(raw []byte) (detail Any, err error) {
-	var w struct {
-		Type string `json:"@type"`
-	}
+func (d Decoder) decodeDetail(raw []byte) (detail Any, dErr *DetailError) {
+	var (
+		w struct {
+			Type string `json:"@type"`
+		}
+		err error
+	)
 	if err = json.Unmarshal(raw, &w); err != nil {
-		return
+		return nil, &DetailError{Err: err}
+	}
+
+	registry := d.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	reg, ok := registry.lookup(w.Type)
+	switch {
+	case ok:
+	case d.Mode == DecodeStrict:
+		return nil, &DetailError{Type: w.Type, Err: ErrUnknownType}
+	default:
+		reg = valueOf[RawDetail]()
 	}
 
-	if provide, ok := typeProvider[w.Type]; ok {
-		detail = provide()
+	target := reg.target()
+	if ok && d.Mode == DecodeStrict {
+		err = unmarshalStrict(raw, target)
 	} else {
-		detail = new(AnyDetail)
+		err = json.Unmarshal(raw, target)
 	}
-	err = json.Unmarshal(raw, detail)
-	return
+	if err != nil {
+		return nil, &DetailError{Type: w.Type, Err: err}
+	}
+	return reg.value(target), nil
+}
+
+// unmarshalStrict rejects unknown fields of struct targets. Other targets,
+// such as maps or custom unmarshalers, see the whole detail including @type.
+func unmarshalStrict(raw []byte, v interface{}) (err error) {
+	if _, ok := v.(json.Unmarshaler); ok || reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Struct {
+		return json.Unmarshal(raw, v)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(raw, &fields); err != nil {
+		return
+	}
+	delete(fields, "@type")
+
+	if raw, err = json.Marshal(fields); err != nil {
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
 }
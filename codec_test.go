@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -52,6 +54,16 @@ func TestEncode(t *testing.T) {
 		encode(rawNoDebugInfo, HideDebugInfo)
 	})
 
+	t.Run("mapping", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(json.NewEncoder(&buf))
+		enc.Mapping.Codes = map[StatusCode]int{FailedPrecondition: http.StatusPreconditionFailed}
+
+		if assert.NoError(t, enc.Encode(Annotate(rootErr, FailedPrecondition))) {
+			assert.Contains(t, buf.String(), `"code":412`)
+		}
+	})
+
 	t.Run("no encoder", func(t *testing.T) {
 		enc := NewEncoder(nil)
 		err := enc.Encode(Internal)
@@ -76,6 +88,28 @@ func TestDecode(t *testing.T) {
 		}
 	})
 
+	t.Run("unknown", func(t *testing.T) {
+		const raw = `{"error":{"code":500,"message":"upstream","status":"INTERNAL","details":[{"@type":"custom/type","id":9007199254740993,"nested":{"a":[1,2.50]}},{"@type":"type.googleapis.com/google.rpc.RequestInfo","requestId":"1"}]}}`
+
+		err := NewDecoder(json.NewDecoder(strings.NewReader(raw))).Decode()
+		if !assert.Error(t, err) {
+			return
+		}
+
+		var buf bytes.Buffer
+		if assert.NoError(t, NewEncoder(json.NewEncoder(&buf)).Encode(err)) {
+			assert.Equal(t, raw, strings.TrimSpace(buf.String()))
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		raw := rawFullError[:len(rawFullError)/2]
+		err := NewDecoder(json.NewDecoder(strings.NewReader(raw))).Decode()
+
+		assert.Equal(t, DataLoss, Code(err))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
 	t.Run("no decoder", func(t *testing.T) {
 		dec := NewDecoder(nil)
 		err := dec.Decode()
@@ -83,10 +117,81 @@ func TestDecode(t *testing.T) {
 	})
 }
 
-func TestCustomType(t *testing.T) {
-	Register(typeUrlCustom, func() Any { return new(AnyDetail) })
-	assert.Contains(t, typeProvider, typeUrlCustom)
+func TestDecodeMode(t *testing.T) {
+	const (
+		rawUnknownType  = `{"error":{"status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.rpc.RequestInfo","requestId":"1"},{"@type":"custom/type","1":"2"}]}}`
+		rawUnknownField = `{"error":{"status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.rpc.RequestInfo","requestId":"1","extra":true}]}}`
+		rawMalformed    = `{"error":{"status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.rpc.RequestInfo","requestId":"1"},{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":1}]}}`
+	)
 
-	Register(typeUrlCustom, nil)
-	assert.NotContains(t, typeProvider, typeUrlCustom)
+	decode := func(raw string, mode DecodeMode) error {
+		dec := NewDecoder(json.NewDecoder(strings.NewReader(raw)))
+		dec.Mode = mode
+		return dec.Decode()
+	}
+
+	assertDetailError := func(err error, index int, typeUrl string) {
+		var dErr *DetailError
+		if assert.ErrorAs(t, err, &dErr) {
+			assert.Equal(t, DataLoss, Code(err))
+			assert.Equal(t, index, dErr.Index)
+			assert.Equal(t, typeUrl, dErr.Type)
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		assert.Equal(t, NotFound, Code(decode(rawUnknownType, DecodeDefault)))
+		assert.Equal(t, NotFound, Code(decode(rawUnknownField, DecodeDefault)))
+		assertDetailError(decode(rawMalformed, DecodeDefault), 1, TypeUrlRetryInfo)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		err := decode(rawUnknownType, DecodeStrict)
+		assert.ErrorIs(t, err, ErrUnknownType)
+		assertDetailError(err, 1, typeUrlCustom)
+
+		err = decode(rawUnknownField, DecodeStrict)
+		assertDetailError(err, 0, TypeUrlRequestInfo)
+		assert.Contains(t, err.Error(), "extra")
+
+		r := NewRegistry()
+		r.Register(typeUrlCustom, func() Any { return new(AnyDetail) })
+		dec := NewDecoder(json.NewDecoder(strings.NewReader(rawUnknownType)), r)
+		dec.Mode = DecodeStrict
+		err = dec.Decode()
+		details := Details(err)
+		if assert.Len(t, details, 2) {
+			assert.Equal(t, typeUrlCustom, details[1].TypeUrl())
+		}
+
+		var buf bytes.Buffer
+		if assert.NoError(t, NewEncoder(json.NewEncoder(&buf)).Encode(err)) {
+			assert.Contains(t, buf.String(), `{"1":"2","@type":"custom/type"}`)
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		err := decode(rawMalformed, DecodeLenient)
+		assert.Equal(t, NotFound, Code(err))
+
+		details := Details(err)
+		if assert.Len(t, details, 2) {
+			bad, ok := details[1].(RawDetail)
+			if assert.True(t, ok) {
+				assert.Equal(t, TypeUrlRetryInfo, bad.TypeUrl())
+				assert.Error(t, bad.Err)
+			}
+		}
+
+		var buf bytes.Buffer
+		if assert.NoError(t, NewEncoder(json.NewEncoder(&buf)).Encode(err)) {
+			assert.Contains(t, buf.String(), `{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":1}`)
+			assert.NotContains(t, buf.String(), "json:")
+		}
+	})
+
+	t.Run("details", func(t *testing.T) {
+		err := decode(`{"error":{"status":"NOT_FOUND","details":{}}}`, DecodeLenient)
+		assert.Equal(t, DataLoss, Code(err))
+	})
 }
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -41,6 +42,62 @@ func (d AnyDetail) TypeUrl() string {
 
 func (d AnyDetail) Annotate(m Modifier) { m.AppendDetails(d) }
 
+// RawDetail keeps a detail as received. Err is set when a registered type
+// failed to decode in lenient mode; it is never encoded.
+type RawDetail struct {
+	Type string
+	Raw  json.RawMessage
+	Err  error
+}
+
+func (d RawDetail) MarshalJSON() ([]byte, error) {
+	if d.Raw == nil {
+		return json.Marshal(AnyDetail{"@type": d.Type})
+	}
+	return d.Raw, nil
+}
+
+func (d *RawDetail) UnmarshalJSON(data []byte) (err error) {
+	var w struct {
+		Type string `json:"@type"`
+	}
+	if err = json.Unmarshal(data, &w); err != nil {
+		return
+	}
+	d.Type = w.Type
+	d.Raw = append(d.Raw[0:0], data...)
+	return
+}
+
+func (d RawDetail) TypeUrl() string     { return d.Type }
+func (d RawDetail) Annotate(m Modifier) { m.AppendDetails(d) }
+
+func (d RawDetail) AnyDetail() (out AnyDetail, err error) {
+	dec := json.NewDecoder(bytes.NewReader(d.Raw))
+	dec.UseNumber()
+	err = dec.Decode(&out)
+	return
+}
+
+func (d RawDetail) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			_, _ = fmt.Fprintf(f, "%s: %q\n", "type", d.TypeUrl())
+			_, _ = fmt.Fprintf(f, "%s: %s\n", "raw", d.Raw)
+			if d.Err != nil {
+				_, _ = fmt.Fprintf(f, "%s: %q\n", "err", d.Err.Error())
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = f.Write(d.Raw)
+	case 'q':
+		_, _ = fmt.Fprintf(f, "%q", d.Raw)
+	}
+}
+
 type Duration time.Duration
 
 func (d Duration) MarshalJSON() ([]byte, error) {
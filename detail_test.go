@@ -85,6 +85,47 @@ func TestDetail(t *testing.T) {
 	})
 }
 
+func TestRawDetail(t *testing.T) {
+	const raw = `{"@type":"custom/type","id":9007199254740993,"nested":{"list":[1,2.50,"3"]}}`
+
+	var d RawDetail
+	if !assert.NoError(t, json.Unmarshal([]byte(raw), &d)) {
+		return
+	}
+	assert.Equal(t, typeUrlCustom, d.TypeUrl())
+
+	data, err := json.Marshal(d)
+	if assert.NoError(t, err) {
+		assert.Equal(t, raw, string(data))
+	}
+
+	m, err := d.AnyDetail()
+	if assert.NoError(t, err) {
+		assert.Equal(t, json.Number("9007199254740993"), m["id"])
+	}
+
+	const html = `{"@type": "custom/type", "link": "/cats?a=1&b=<2>"}`
+	var escaped RawDetail
+	if assert.NoError(t, json.Unmarshal([]byte(html), &escaped)) {
+		data, err = json.Marshal(message{messageBody{Details: []Any{escaped}}})
+		if assert.NoError(t, err) {
+			assert.Contains(t, string(data), `"link":"/cats?a=1\u0026b=\u003c2\u003e"`)
+			assert.JSONEq(t, `{"error":{"details":[`+html+`]}}`, string(data))
+		}
+	}
+
+	data, err = json.Marshal(RawDetail{Type: typeUrlCustom})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"@type":"custom/type"}`, string(data))
+	}
+
+	for _, verb := range []string{"%+v", "%v", "%s", "%q"} {
+		str := fmt.Sprintf(verb, d)
+		assert.Contains(t, str, "9007199254740993")
+		assert.NotContains(t, str, "!")
+	}
+}
+
 func TestStackTrace(t *testing.T) {
 	m := &MockModifier{}
 	stack := StackTrace(msg)
@@ -232,6 +232,17 @@ Loop:
 			if v.OnDetails != nil && !v.OnDetails(a.details) {
 				break Loop
 			}
+		case *propagated:
+			if v.OnCode != nil && !v.OnCode(a.code) {
+				break Loop
+			}
+			if v.OnDetails != nil && !v.OnDetails(a.details) {
+				break Loop
+			}
+			if v.OnError != nil {
+				v.OnError(cur)
+			}
+			break Loop
 		}
 		if v.OnError != nil && !v.OnError(cur) {
 			break Loop
@@ -0,0 +1,14 @@
+// Command errorslint reports lost status codes and misuse of
+// github.com/gota33/errors.
+//
+//	go vet -vettool=$(which errorslint) ./...
+package main
+
+import (
+	"github.com/gota33/errors/errorslint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(errorslint.Analyzer)
+}
@@ -0,0 +1,300 @@
+package errorslint
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const errorsPath = "github.com/gota33/errors"
+
+var Analyzer = &analysis.Analyzer{
+	Name: "errorslint",
+	Doc: `check for lost status codes and misuse of github.com/gota33/errors
+
+The analyzer reports:
+  - fmt.Errorf formatting an error with a verb other than %w, which drops the
+    chain that errors.Code relies on;
+  - errors.Annotate called without annotations;
+  - With* constructors called with a nil or typed-nil cause;
+  - HTTP handlers (func(http.ResponseWriter, *http.Request) error) returning
+    errors that never get a status;
+  - errors.Register and errors.RegisterType on the default registry outside init.`,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if pass.Pkg.Path() == errorsPath {
+		return nil, nil
+	}
+
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filter := []ast.Node{(*ast.CallExpr)(nil), (*ast.ReturnStmt)(nil)}
+
+	ins.WithStack(filter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			checkCall(pass, n, stack)
+		case *ast.ReturnStmt:
+			checkReturn(pass, n, stack)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+func checkCall(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) {
+	obj := typeutil.Callee(pass.TypesInfo, call)
+	switch {
+	case isFunc(obj, "fmt", "Errorf"):
+		checkErrorf(pass, call)
+	case isFunc(obj, errorsPath, "Annotate"):
+		if len(call.Args) == 1 && !call.Ellipsis.IsValid() {
+			pass.Reportf(call.Pos(), "errors.Annotate without annotations returns the error unchanged")
+		}
+	case isFunc(obj, errorsPath, "Register"),
+		isFunc(obj, errorsPath, "RegisterType") && len(call.Args) == 0,
+		isMethod(obj, errorsPath, "Registry", "Register") && isDefaultRegistry(pass, call):
+		if !inInit(stack) {
+			pass.Reportf(call.Pos(), "%s should be called from init so the registry is complete before any payload is decoded", obj.Name())
+		}
+	case isConstructor(obj):
+		checkCause(pass, call, obj.Name())
+	}
+}
+
+func checkErrorf(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return
+	}
+	verbs, ok := formatVerbs(pass, call.Args[0])
+	if !ok {
+		return
+	}
+
+	for i, verb := range verbs {
+		if i+1 >= len(call.Args) {
+			return
+		}
+		arg := call.Args[i+1]
+		if verb == 'w' || verb == '*' || !isError(pass.TypesInfo.TypeOf(arg)) {
+			continue
+		}
+		pass.Reportf(arg.Pos(), "fmt.Errorf formats an error with %%%c, which drops the status chain used by errors.Code; use %%w", verb)
+	}
+}
+
+func checkCause(pass *analysis.Pass, call *ast.CallExpr, name string) {
+	if len(call.Args) == 0 {
+		return
+	}
+	cause := call.Args[0]
+	if tv, ok := pass.TypesInfo.Types[cause]; ok && tv.IsNil() {
+		pass.Reportf(cause.Pos(), "errors.%s(nil, ...) returns nil and the error is lost", name)
+		return
+	}
+
+	if !isVariable(pass, cause) {
+		return
+	}
+	t := pass.TypesInfo.TypeOf(cause)
+	if t == nil || types.IsInterface(t) {
+		return
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Map, *types.Slice, *types.Chan, *types.Signature:
+		pass.Reportf(cause.Pos(), "errors.%s called with a %s cause; a typed nil is not caught by the nil check", name, types.TypeString(t, types.RelativeTo(pass.Pkg)))
+	}
+}
+
+func checkReturn(pass *analysis.Pass, ret *ast.ReturnStmt, stack []ast.Node) {
+	if len(ret.Results) != 1 || !isHandler(pass, enclosingFunc(stack)) {
+		return
+	}
+
+	call, ok := ast.Unparen(ret.Results[0]).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	obj := typeutil.Callee(pass.TypesInfo, call)
+	switch {
+	case isFunc(obj, "errors", "New"), isVar(obj, errorsPath, "New"):
+	case isFunc(obj, "fmt", "Errorf"):
+		if verbs, ok := formatVerbs(pass, call.Args[0]); !ok || strings.ContainsRune(string(verbs), 'w') {
+			return
+		}
+	default:
+		return
+	}
+	pass.Reportf(call.Pos(), "error returned from HTTP handler has no status and is served as UNKNOWN; annotate it with a status code")
+}
+
+// formatVerbs returns the verbs of a constant format string in argument
+// order. '*' stands for a width or precision argument. Explicit argument
+// indexes are not supported.
+func formatVerbs(pass *analysis.Pass, expr ast.Expr) (verbs []rune, ok bool) {
+	tv, found := pass.TypesInfo.Types[expr]
+	if !found || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return nil, false
+	}
+	format := constant.StringVal(tv.Value)
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '[' {
+				return nil, false
+			}
+			if c == '*' {
+				verbs = append(verbs, '*')
+			} else if !strings.ContainsRune("+-# 0.123456789", rune(c)) {
+				break
+			}
+		}
+		if i >= len(format) {
+			break
+		}
+		if format[i] == '%' {
+			continue
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		verbs = append(verbs, verb)
+		i += size - 1
+	}
+	return verbs, true
+}
+
+func isHandler(pass *analysis.Pass, fn ast.Node) bool {
+	var t types.Type
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		if obj := pass.TypesInfo.Defs[fn.Name]; obj != nil {
+			t = obj.Type()
+		}
+	case *ast.FuncLit:
+		t = pass.TypesInfo.TypeOf(fn)
+	}
+
+	sig, ok := t.(*types.Signature)
+	if !ok || sig.Params().Len() != 2 || sig.Results().Len() != 1 {
+		return false
+	}
+	return isNamed(sig.Params().At(0).Type(), "net/http", "ResponseWriter") &&
+		isNamed(sig.Params().At(1).Type(), "net/http", "Request") &&
+		types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+func isNamed(t types.Type, pkg, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkg && obj.Name() == name
+}
+
+func isFunc(obj types.Object, pkg, name string) bool {
+	fn, ok := obj.(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == pkg && fn.Name() == name &&
+		fn.Type().(*types.Signature).Recv() == nil
+}
+
+func isVar(obj types.Object, pkg, name string) bool {
+	v, ok := obj.(*types.Var)
+	return ok && v.Pkg() != nil && v.Pkg().Path() == pkg && v.Name() == name && !v.IsField()
+}
+
+func isMethod(obj types.Object, pkg, recv, name string) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkg || fn.Name() != name {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Recv() != nil && isNamed(sig.Recv().Type(), pkg, recv)
+}
+
+func isConstructor(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPath || !strings.HasPrefix(fn.Name(), "With") {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Recv() == nil && sig.Params().Len() > 0 &&
+		types.Identical(sig.Params().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+func isDefaultRegistry(pass *analysis.Pass, call *ast.CallExpr) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	var id *ast.Ident
+	switch x := ast.Unparen(sel.X).(type) {
+	case *ast.Ident:
+		id = x
+	case *ast.SelectorExpr:
+		id = x.Sel
+	default:
+		return false
+	}
+	return isVar(pass.TypesInfo.Uses[id], errorsPath, "DefaultRegistry")
+}
+
+// isVariable reports whether expr names a variable, parameter or field, the
+// only causes that may hold a typed nil. Literals such as &T{} and call
+// results are not checked.
+func isVariable(pass *analysis.Pass, expr ast.Expr) bool {
+	var id *ast.Ident
+	switch x := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		id = x
+	case *ast.SelectorExpr:
+		id = x.Sel
+	default:
+		return false
+	}
+	_, ok := pass.TypesInfo.Uses[id].(*types.Var)
+	return ok
+}
+
+func isError(t types.Type) bool {
+	return t != nil && types.Implements(t, errorType)
+}
+
+func enclosingFunc(stack []ast.Node) ast.Node {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return stack[i]
+		}
+	}
+	return nil
+}
+
+func inInit(stack []ast.Node) bool {
+	for _, n := range stack {
+		if fn, ok := n.(*ast.FuncDecl); ok {
+			return fn.Recv == nil && fn.Name.Name == "init"
+		}
+	}
+	return true
+}
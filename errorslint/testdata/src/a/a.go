@@ -0,0 +1,77 @@
+package a
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gota33/errors"
+)
+
+type myErr struct{}
+
+func (*myErr) Error() string { return "my" }
+
+func init() {
+	errors.Register("example.com/Init", nil)
+	_ = errors.RegisterType[errors.ResourceInfo]()
+	errors.DefaultRegistry.Register("example.com/Init", nil)
+}
+
+var _ = errors.RegisterType[errors.ResourceInfo]()
+
+func wrap(err error) error {
+	_ = fmt.Errorf("load: %w", err)
+	_ = fmt.Errorf("load %s: %v", "cat", err)    // want `fmt.Errorf formats an error with %v, which drops the status chain used by errors.Code; use %w`
+	_ = fmt.Errorf("load %*d: %s %%", 3, 1, err) // want `fmt.Errorf formats an error with %s`
+	_ = fmt.Errorf("load: %[1]v", err)
+	_ = fmt.Errorf("load: %v", err.Error())
+	return fmt.Errorf("load: %w: %v", err, errors.NotFound) // want `fmt.Errorf formats an error with %v`
+}
+
+func annotate(err error, typed *myErr) {
+	_ = errors.Annotate(err) // want `errors.Annotate without annotations returns the error unchanged`
+	_ = errors.Annotate(err, errors.NotFound)
+	_ = errors.WithNotFound(nil, errors.ResourceInfo{}) // want `errors.WithNotFound\(nil, ...\) returns nil and the error is lost`
+	_ = errors.WithCancelled(typed)                     // want `errors.WithCancelled called with a \*myErr cause; a typed nil is not caught by the nil check`
+	_ = errors.WithCancelled(err)
+	_ = errors.WithCancelled(&myErr{})
+	_ = errors.WithCancelled(newMyErr())
+
+	h := holder{cause: typed}
+	_ = errors.WithCancelled(h.cause) // want `errors.WithCancelled called with a \*myErr cause`
+}
+
+type holder struct{ cause *myErr }
+
+func newMyErr() *myErr { return &myErr{} }
+
+func register() {
+	errors.Register("example.com/Late", nil)                 // want `Register should be called from init`
+	_ = errors.RegisterType[errors.ResourceInfo]()           // want `RegisterType should be called from init`
+	errors.DefaultRegistry.Register("example.com/Late", nil) // want `Register should be called from init`
+	_ = errors.RegisterType[errors.ResourceInfo](errors.NewRegistry())
+	errors.NewRegistry().Register("example.com/Local", nil)
+}
+
+func handle(w http.ResponseWriter, r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet:
+		return stderrors.New("missing") // want `error returned from HTTP handler has no status`
+	case http.MethodPost:
+		return errors.New("missing") // want `error returned from HTTP handler has no status`
+	case http.MethodPut:
+		return fmt.Errorf("missing %s", "cat") // want `error returned from HTTP handler has no status`
+	case http.MethodDelete:
+		return fmt.Errorf("missing: %w", errors.NotFound)
+	}
+	return errors.Annotate(stderrors.New("missing"), errors.NotFound)
+}
+
+var handler = func(w http.ResponseWriter, r *http.Request) error {
+	return stderrors.New("missing") // want `error returned from HTTP handler has no status`
+}
+
+func notHandler() error {
+	return stderrors.New("missing")
+}
@@ -0,0 +1,38 @@
+package errors
+
+import "errors"
+
+var New = errors.New
+
+type StatusCode int
+
+const NotFound StatusCode = 5
+
+func (c StatusCode) Error() string          { return "status" }
+func (c StatusCode) Annotate(m interface{}) {}
+
+type Annotation interface{ Annotate(m interface{}) }
+
+type Any interface{ Annotation }
+
+type ResourceInfo struct{}
+
+func (ResourceInfo) Annotate(m interface{}) {}
+
+func Annotate(cause error, annotations ...Annotation) error { return cause }
+
+func WithNotFound(cause error, detail ResourceInfo) error { return cause }
+
+func WithCancelled(cause error) error { return cause }
+
+type Registry struct{}
+
+func (r *Registry) Register(typeUrl string, provider func() Any) {}
+
+func NewRegistry() *Registry { return &Registry{} }
+
+var DefaultRegistry = NewRegistry()
+
+func Register(typeUrl string, provider func() Any) {}
+
+func RegisterType[T Any](registry ...*Registry) error { return nil }
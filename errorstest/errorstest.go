@@ -0,0 +1,101 @@
+package errorstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gota33/errors"
+)
+
+func AssertCode(t testing.TB, err error, code errors.StatusCode) bool {
+	t.Helper()
+
+	if actual := errors.Code(err); actual != code {
+		return fail(t, err, "expected code %s, got %s", code, actual)
+	}
+	return true
+}
+
+func AssertDetail[T errors.Any](t testing.TB, err error) (detail T, ok bool) {
+	t.Helper()
+
+	if detail, ok = Detail[T](err); !ok {
+		fail(t, err, "expected detail %T", detail)
+	}
+	return
+}
+
+func Detail[T errors.Any](err error) (detail T, ok bool) {
+	for _, d := range errors.Details(err) {
+		if detail, ok = d.(T); ok {
+			return
+		}
+	}
+	return
+}
+
+func AssertFieldViolation(t testing.TB, err error, field string) bool {
+	t.Helper()
+
+	for _, d := range errors.Details(err) {
+		if br, ok := d.(errors.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				if v.Field == field {
+					return true
+				}
+			}
+		}
+	}
+	return fail(t, err, "expected field violation for %q", field)
+}
+
+func AssertReason(t testing.TB, err error, domain, reason string) bool {
+	t.Helper()
+
+	for _, d := range errors.Details(err) {
+		if info, ok := d.(errors.ErrorInfo); ok && info.Domain == domain && info.Reason == reason {
+			return true
+		}
+	}
+	return fail(t, err, "expected error info [%s] %s", domain, reason)
+}
+
+func AssertHTTPResponse(t testing.TB, rec *httptest.ResponseRecorder, code errors.StatusCode, details ...errors.Any) bool {
+	t.Helper()
+
+	if rec.Code != code.Http() {
+		t.Errorf("expected HTTP status %d, got %d\nbody: %s", code.Http(), rec.Code, rec.Body.String())
+		return false
+	}
+
+	err := errors.NewDecoder(json.NewDecoder(rec.Body)).Decode()
+	if !AssertCode(t, err, code) {
+		return false
+	}
+
+	decoded := errors.Details(err)
+	for _, expected := range details {
+		if !contains(decoded, expected) {
+			return fail(t, err, "expected detail %+v", expected)
+		}
+	}
+	return true
+}
+
+func contains(details []errors.Any, detail errors.Any) bool {
+	for _, d := range details {
+		if reflect.DeepEqual(d, detail) {
+			return true
+		}
+	}
+	return false
+}
+
+func fail(t testing.TB, err error, format string, args ...interface{}) bool {
+	t.Helper()
+	t.Errorf("%s\nerror:\n%+v", fmt.Sprintf(format, args...), err)
+	return false
+}
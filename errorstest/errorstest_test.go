@@ -0,0 +1,95 @@
+package errorstest
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gota33/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockT struct {
+	testing.TB
+	errors []string
+}
+
+func (m *mockT) Helper() {}
+
+func (m *mockT) Errorf(format string, args ...interface{}) {
+	m.errors = append(m.errors, fmt.Sprintf(format, args...))
+}
+
+var (
+	resourceInfo = errors.ResourceInfo{ResourceType: "cat", ResourceName: "1"}
+	errInfo      = errors.ErrorInfo{Domain: "pet.com", Reason: "CAT_NOT_FOUND"}
+	badRequest   = errors.BadRequest{FieldViolations: []errors.FieldViolation{{Field: "name", Description: "required"}}}
+	testErr      = errors.Annotate(sql.ErrNoRows, errors.NotFound, resourceInfo, errInfo, badRequest)
+)
+
+func TestAssertCode(t *testing.T) {
+	assert.True(t, AssertCode(t, testErr, errors.NotFound))
+
+	m := &mockT{}
+	assert.False(t, AssertCode(m, testErr, errors.Internal))
+	if assert.Len(t, m.errors, 1) {
+		assert.Contains(t, m.errors[0], "expected code 500 INTERNAL, got 404 NOT_FOUND")
+		assert.Contains(t, m.errors[0], `status: "404 NOT_FOUND"`)
+	}
+}
+
+func TestAssertDetail(t *testing.T) {
+	detail, ok := AssertDetail[errors.ResourceInfo](t, testErr)
+	assert.True(t, ok)
+	assert.Equal(t, resourceInfo, detail)
+
+	m := &mockT{}
+	_, ok = AssertDetail[errors.RetryInfo](m, testErr)
+	assert.False(t, ok)
+	if assert.Len(t, m.errors, 1) {
+		assert.Contains(t, m.errors[0], "expected detail errors.RetryInfo")
+	}
+}
+
+func TestAssertFieldViolation(t *testing.T) {
+	assert.True(t, AssertFieldViolation(t, testErr, "name"))
+
+	m := &mockT{}
+	assert.False(t, AssertFieldViolation(m, testErr, "age"))
+	assert.Len(t, m.errors, 1)
+}
+
+func TestAssertReason(t *testing.T) {
+	assert.True(t, AssertReason(t, testErr, "pet.com", "CAT_NOT_FOUND"))
+
+	m := &mockT{}
+	assert.False(t, AssertReason(m, testErr, "pet.com", "DOG_NOT_FOUND"))
+	assert.Len(t, m.errors, 1)
+}
+
+func TestAssertHTTPResponse(t *testing.T) {
+	h := &errors.Handler{
+		Handle: func(w http.ResponseWriter, r *http.Request) error {
+			return testErr
+		},
+	}
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	assert.True(t, AssertHTTPResponse(t, serve(), errors.NotFound, resourceInfo, errInfo))
+
+	m := &mockT{}
+	assert.False(t, AssertHTTPResponse(m, serve(), errors.Internal))
+	assert.False(t, AssertHTTPResponse(m, serve(), errors.NotFound, errors.RetryInfo{}))
+	assert.Len(t, m.errors, 2)
+
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(http.StatusNotFound)
+	assert.False(t, AssertHTTPResponse(m, rec, errors.NotFound))
+	assert.Len(t, m.errors, 3)
+}
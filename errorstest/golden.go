@@ -0,0 +1,124 @@
+package errorstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gota33/errors"
+)
+
+const (
+	NormalizedRequestId  = "{request-id}"
+	NormalizedStackEntry = "{stack}"
+)
+
+type GoldenFormat int
+
+const (
+	GoldenJSON GoldenFormat = iota
+	GoldenText
+)
+
+func (f GoldenFormat) ext() string {
+	if f == GoldenText {
+		return ".txt"
+	}
+	return ".json"
+}
+
+// Update makes every Golden rewrite its file instead of comparing. Wire it to
+// a flag in the calling package, for example:
+//
+//	func init() { flag.BoolVar(&errorstest.Update, "update", false, "update golden files") }
+var Update bool
+
+type Golden struct {
+	Dir     string
+	Format  GoldenFormat
+	Mappers []errors.DetailMapper
+	Update  bool
+}
+
+func AssertGolden(t testing.TB, name string, err error, mappers ...errors.DetailMapper) bool {
+	t.Helper()
+
+	return Golden{Mappers: mappers}.Assert(t, name, err)
+}
+
+func (g Golden) Assert(t testing.TB, name string, err error) bool {
+	t.Helper()
+
+	actual, mErr := g.Marshal(err)
+	if mErr != nil {
+		t.Errorf("errorstest: marshal golden %q: %v", name, mErr)
+		return false
+	}
+
+	dir := g.Dir
+	if dir == "" {
+		dir = "testdata"
+	}
+	path := filepath.Join(dir, name+g.Format.ext())
+
+	if g.Update || Update {
+		if wErr := os.MkdirAll(filepath.Dir(path), 0o755); wErr != nil {
+			t.Errorf("errorstest: update golden %q: %v", name, wErr)
+			return false
+		}
+		if wErr := os.WriteFile(path, actual, 0o644); wErr != nil {
+			t.Errorf("errorstest: update golden %q: %v", name, wErr)
+			return false
+		}
+		return true
+	}
+
+	expected, rErr := os.ReadFile(path)
+	if rErr != nil {
+		t.Errorf("errorstest: read golden %q: %v (set Update to create it)", name, rErr)
+		return false
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("errorstest: golden %q mismatch\nexpected:\n%s\nactual:\n%s", path, expected, actual)
+		return false
+	}
+	return true
+}
+
+func (g Golden) Marshal(err error) ([]byte, error) {
+	mappers := append(append([]errors.DetailMapper(nil), g.Mappers...), Normalize)
+
+	var buf bytes.Buffer
+	switch g.Format {
+	case GoldenText:
+		fmt.Fprintf(&buf, "%+v", errors.Flatten(err, mappers...))
+	default:
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		encoder := errors.NewEncoder(enc)
+		encoder.Mappers = mappers
+		if eErr := encoder.Encode(err); eErr != nil {
+			return nil, eErr
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func Normalize(a errors.Any) errors.Any {
+	switch d := a.(type) {
+	case errors.RequestInfo:
+		if d.RequestId != "" {
+			d.RequestId = NormalizedRequestId
+		}
+		return d
+	case errors.DebugInfo:
+		if len(d.StackEntries) > 0 {
+			d.StackEntries = []string{NormalizedStackEntry}
+		}
+		return d
+	}
+	return a
+}
@@ -0,0 +1,60 @@
+package errorstest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gota33/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func goldenError(requestId string, stack ...string) error {
+	return errors.Annotate(errors.New("cat not found"), errors.NotFound,
+		errors.ResourceInfo{ResourceType: "cat", ResourceName: "cat123"},
+		errors.RequestInfo{RequestId: requestId},
+		errors.DebugInfo{StackEntries: stack, Detail: "lookup"},
+	)
+}
+
+func init() {
+	flag.BoolVar(&Update, "update", false, "update golden files")
+}
+
+func TestGolden(t *testing.T) {
+	assert.True(t, AssertGolden(t, "not_found", goldenError("req-1", "main.go:12")))
+	assert.True(t, AssertGolden(t, "not_found", goldenError("req-2", "main.go:40", "http.go:7")))
+	assert.True(t, AssertGolden(t, "not_found_public", goldenError("req-3"), errors.HideDebugInfo))
+	assert.True(t, Golden{Format: GoldenText}.Assert(t, "not_found", goldenError("req-4", "main.go:1")))
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	update := Update
+	Update = false
+	defer func() { Update = update }()
+
+	dir := t.TempDir()
+	g := Golden{Dir: dir}
+
+	m := &mockT{}
+	assert.False(t, g.Assert(m, "missing", goldenError("req")))
+	assert.NotEmpty(t, m.errors)
+
+	data, _ := g.Marshal(goldenError("req"))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "changed.json"), data, 0o644))
+
+	m = &mockT{}
+	assert.True(t, g.Assert(m, "changed", goldenError("other-req")))
+	assert.Empty(t, m.errors)
+
+	m = &mockT{}
+	assert.False(t, g.Assert(m, "changed", errors.Annotate(goldenError("req"), errors.Internal)))
+	assert.NotEmpty(t, m.errors)
+
+	g.Update = true
+	m = &mockT{}
+	assert.True(t, g.Assert(m, "missing", goldenError("req")))
+	assert.Empty(t, m.errors)
+	assert.FileExists(t, filepath.Join(dir, "missing.json"))
+}
@@ -0,0 +1,105 @@
+package errorstest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/gota33/errors"
+)
+
+var ErrScriptExhausted = errors.New("errorstest: script exhausted")
+
+type Step struct {
+	Err    error
+	Status int
+	Body   string
+	Header http.Header
+	Delay  time.Duration
+}
+
+type Request struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+type Server struct {
+	*httptest.Server
+	Mappers []errors.DetailMapper
+
+	mu       sync.Mutex
+	steps    []Step
+	requests []Request
+}
+
+func NewServer(steps ...Step) *Server {
+	s := &Server{steps: steps}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		URL:    r.URL.RequestURI(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+
+	step := Step{Err: errors.Annotate(ErrScriptExhausted, errors.Internal)}
+	if len(s.steps) > 0 {
+		step, s.steps = s.steps[0], s.steps[1:]
+	}
+	s.mu.Unlock()
+
+	if step.Delay > 0 {
+		timer := time.NewTimer(step.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+		}
+	}
+
+	for name, values := range step.Header {
+		w.Header()[name] = values
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	status := step.Status
+	if status == 0 {
+		status = errors.Code(step.Err).Http()
+		if step.Err == nil {
+			status = http.StatusOK
+		}
+	}
+	w.WriteHeader(status)
+
+	if step.Body != "" || step.Err == nil {
+		_, _ = io.WriteString(w, step.Body)
+		return
+	}
+
+	enc := errors.NewEncoder(json.NewEncoder(w))
+	enc.Mappers = s.Mappers
+	_ = enc.Encode(step.Err)
+}
@@ -0,0 +1,98 @@
+package errorstest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gota33/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("retry", func(t *testing.T) {
+		srv := NewServer(
+			Step{Err: errors.Annotate(errors.New("busy"), errors.Unavailable, errors.RetryInfo{RetryDelay: errors.Duration(time.Millisecond)})},
+			Step{Err: errors.New("slow down"), Status: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"0"}}},
+			Step{Body: `{"data":"ok"}`},
+		)
+		defer srv.Close()
+
+		client := http.Client{
+			Transport: &errors.RetryTransport{
+				Parent: &errors.RoundTripper{Overrides: map[int]errors.StatusCode{http.StatusTooManyRequests: errors.ResourceExhausted}},
+			},
+		}
+
+		resp, err := client.Post(srv.URL+"/v1/cats?x=1", "text/plain", strings.NewReader("payload"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, `{"data":"ok"}`, string(data))
+
+		requests := srv.Requests()
+		if assert.Len(t, requests, 3) {
+			for _, req := range requests {
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, "/v1/cats?x=1", req.URL)
+				assert.Equal(t, "payload", string(req.Body))
+				assert.Equal(t, "text/plain", req.Header.Get("Content-Type"))
+			}
+		}
+	})
+
+	t.Run("encoded", func(t *testing.T) {
+		srv := NewServer(Step{Err: errors.WithNotFound(errors.New("cat"), errors.ResourceInfo{ResourceName: "1"})})
+		defer srv.Close()
+		srv.Mappers = []errors.DetailMapper{errors.HideDebugInfo}
+
+		resp, err := http.Get(srv.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		err = errors.NewDecoder(json.NewDecoder(resp.Body)).Decode()
+		AssertCode(t, err, errors.NotFound)
+		AssertDetail[errors.ResourceInfo](t, err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		srv := NewServer(Step{Status: http.StatusBadGateway, Body: `{"error":`})
+		defer srv.Close()
+
+		client := http.Client{Transport: &errors.RoundTripper{}}
+		_, err := client.Get(srv.URL)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		AssertCode(t, err, errors.Internal)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		srv := NewServer(Step{Delay: time.Second})
+		defer srv.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		_, err := (&http.Client{Transport: &errors.RoundTripper{}}).Do(req)
+		AssertCode(t, err, errors.DeadlineExceeded)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		srv := NewServer()
+		defer srv.Close()
+
+		client := http.Client{Transport: &errors.RoundTripper{}}
+		_, err := client.Get(srv.URL)
+		AssertCode(t, err, errors.Internal)
+		assert.Contains(t, err.Error(), ErrScriptExhausted.Error())
+	})
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
+
+const DefaultFingerprintFrames = 3
+
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexPattern    = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{8,}\b`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+func Fingerprint(err error) string {
+	return Fingerprinter{}.Fingerprint(err)
+}
+
+type Fingerprinter struct {
+	Module string
+	Frames int
+}
+
+func (f Fingerprinter) Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	a := Flatten(err).(*annotated)
+	fields := []string{a.code.String(), "", "", NormalizeMessage(a.message)}
+
+	var infoSeen, stackSeen bool
+	for _, detail := range a.details {
+		switch d := detail.(type) {
+		case ErrorInfo:
+			if !infoSeen {
+				infoSeen = true
+				fields[1], fields[2] = d.Domain, d.Reason
+			}
+		case DebugInfo:
+			if !stackSeen && len(d.StackEntries) > 0 {
+				stackSeen = true
+				fields = append(fields, f.frames(d.StackEntries)...)
+			}
+		}
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (f Fingerprinter) frames(entries []string) (out []string) {
+	module := f.Module
+	if module == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			module = info.Main.Path
+		}
+	}
+
+	limit := f.Frames
+	if limit <= 0 {
+		limit = DefaultFingerprintFrames
+	}
+
+	for _, entry := range entries {
+		if len(out) >= limit {
+			break
+		}
+		if entry == "" || strings.HasPrefix(entry, "\t") || strings.HasPrefix(entry, "goroutine ") {
+			continue
+		}
+
+		fn := entry
+		if i := strings.LastIndexByte(fn, '('); i > 0 {
+			fn = fn[:i]
+		}
+		if module == "" || strings.HasPrefix(fn, module+".") || strings.HasPrefix(fn, module+"/") {
+			out = append(out, fn)
+		}
+	}
+	return
+}
+
+func NormalizeMessage(msg string) string {
+	msg = uuidPattern.ReplaceAllString(msg, "<id>")
+	msg = hexPattern.ReplaceAllString(msg, "<id>")
+	return numberPattern.ReplaceAllString(msg, "<n>")
+}
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	for in, out := range map[string]string{
+		"user 123 not found": "user <n> not found",
+		"order 5f2b1c9e-8d1a-4c3b-9e7f-0a1b2c3d4e5f is closed":   "order <id> is closed",
+		"object 0xc000123456 at deadbeef00 failed after 3 tries": "object <id> at <id> failed after <n> tries",
+		"plain message": "plain message",
+	} {
+		assert.Equal(t, out, NormalizeMessage(in))
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	stack := func(line string) DebugInfo {
+		return DebugInfo{
+			StackEntries: []string{
+				"goroutine 7 [running]:",
+				"runtime/debug.Stack()",
+				"\t/usr/local/go/src/runtime/debug/stack.go:24 +0x5e",
+				"example.com/app/store.(*Cats).Get(0xc0000a6000, {0x10, 0x3})",
+				"\t/src/app/store/cats.go:" + line + " +0x25",
+				"example.com/app/api.getCat(...)",
+				"\t/src/app/api/cats.go:" + line,
+				"example.com/app.main()",
+				"example.com/app/cmd.run()",
+				"net/http.HandlerFunc.ServeHTTP(0x0)",
+			},
+		}
+	}
+	fp := Fingerprinter{Module: "example.com/app"}
+
+	base := fp.Fingerprint(Annotate(errors.New("cat 1 not found"), NotFound,
+		ErrorInfo{Domain: "pet.com", Reason: "CAT_NOT_FOUND"},
+		RequestInfo{RequestId: "a"},
+		stack("10"),
+	))
+	assert.Len(t, base, 16)
+
+	same := fp.Fingerprint(Annotate(errors.New("cat 2 not found"), NotFound,
+		RequestInfo{RequestId: "b"},
+		ErrorInfo{Domain: "pet.com", Reason: "CAT_NOT_FOUND"},
+		stack("42"),
+	))
+	assert.Equal(t, base, same)
+
+	for _, err := range []error{
+		Annotate(errors.New("cat 1 not found"), Internal, ErrorInfo{Domain: "pet.com", Reason: "CAT_NOT_FOUND"}, stack("10")),
+		Annotate(errors.New("cat 1 not found"), NotFound, ErrorInfo{Domain: "pet.com", Reason: "DOG_NOT_FOUND"}, stack("10")),
+		Annotate(errors.New("cat 1 is gone"), NotFound, ErrorInfo{Domain: "pet.com", Reason: "CAT_NOT_FOUND"}, stack("10")),
+		Annotate(errors.New("cat 1 not found"), NotFound, ErrorInfo{Domain: "pet.com", Reason: "CAT_NOT_FOUND"}),
+	} {
+		assert.NotEqual(t, base, fp.Fingerprint(err), err.Error())
+	}
+
+	assert.Equal(t, []string{
+		"example.com/app/store.(*Cats).Get",
+		"example.com/app/api.getCat",
+		"example.com/app.main",
+	}, fp.frames(stack("1").StackEntries))
+
+	assert.Empty(t, Fingerprint(nil))
+	assert.Equal(t, Fingerprint(Annotate(errors.New("a"), StackTrace("x"))), Fingerprint(Annotate(errors.New("a"), StackTrace("y"))))
+}
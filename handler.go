@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+type Handler struct {
+	Handle   HandlerFunc
+	Route    string
+	Mappers  []DetailMapper
+	Mapping  HttpMapping
+	Metrics  MetricSink
+	Tracer   Tracer
+	Reporter *Reporter
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := h.serve(w, r)
+
+	if h.Reporter != nil {
+		h.Reporter.Report(h.Route, err)
+	}
+	if h.Metrics != nil {
+		h.Metrics.Add(NewMetric(MetricServer, h.Route, err), 1)
+	}
+	if err != nil {
+		h.writeError(w, r, err)
+	}
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			err = PanicError(v)
+		}
+	}()
+	return h.Handle(w, r)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(h.Mapping.Http(Code(err)))
+
+	enc := NewEncoder(json.NewEncoder(w))
+	enc.Mappers = h.Mappers
+	enc.Mapping = h.Mapping
+	enc.Tracer = h.Tracer
+	_ = enc.EncodeContext(r.Context(), err)
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	var metrics []Metric
+	h := &Handler{
+		Route:   "/v1/cats",
+		Mappers: []DetailMapper{HideDebugInfo},
+		Mapping: HttpMapping{Codes: map[StatusCode]int{FailedPrecondition: http.StatusPreconditionFailed}},
+		Metrics: MetricSinkFunc(func(m Metric, delta int64) {
+			metrics = append(metrics, m)
+		}),
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		h.Handle = func(w http.ResponseWriter, r *http.Request) error {
+			_, _ = io.WriteString(w, "ok")
+			return nil
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/cats", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "ok", rec.Body.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		h.Handle = func(w http.ResponseWriter, r *http.Request) error {
+			return Annotate(sql.ErrNoRows, FailedPrecondition, debugInfo, errInfo)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/cats", nil))
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+		err := NewDecoder(json.NewDecoder(rec.Body)).Decode()
+		assert.Equal(t, FailedPrecondition, Code(err))
+		assert.Equal(t, []Any{errInfo}, Details(err))
+	})
+
+	assert.Equal(t, []Metric{
+		{Side: MetricServer, Route: "/v1/cats"},
+		{Side: MetricServer, Route: "/v1/cats", Status: FailedPrecondition, Domain: errInfo.Domain, Reason: errInfo.Reason},
+	}, metrics)
+}
@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"expvar"
+	"sync"
+)
+
+const (
+	MetricClient = "client"
+	MetricServer = "server"
+)
+
+type Metric struct {
+	Side   string
+	Route  string
+	Status StatusCode
+	Domain string
+	Reason string
+}
+
+func NewMetric(side, route string, err error) Metric {
+	m := Metric{Side: side, Route: route}
+	if err == nil {
+		return m
+	}
+
+	m.Status = Code(err)
+	for _, detail := range Details(err) {
+		if info, ok := detail.(ErrorInfo); ok {
+			m.Domain = info.Domain
+			m.Reason = info.Reason
+			break
+		}
+	}
+	return m
+}
+
+type MetricSink interface {
+	Add(m Metric, delta int64)
+}
+
+type MetricSinkFunc func(m Metric, delta int64)
+
+func (fn MetricSinkFunc) Add(m Metric, delta int64) { fn(m, delta) }
+
+type ExpvarSink struct {
+	status *expvar.Map
+	reason *expvar.Map
+	route  *expvar.Map
+}
+
+var expvarMu sync.Mutex
+
+// NewExpvarSink publishes counters under name. Sinks created with the same name
+// share their counters. It panics if name is taken by a variable other than an
+// *expvar.Map.
+func NewExpvarSink(name string) *ExpvarSink {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	root, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		root = expvar.NewMap(name)
+	}
+	return &ExpvarSink{
+		status: childMap(root, "status"),
+		reason: childMap(root, "reason"),
+		route:  childMap(root, "route"),
+	}
+}
+
+func childMap(root *expvar.Map, key string) *expvar.Map {
+	if m, ok := root.Get(key).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map).Init()
+	root.Set(key, m)
+	return m
+}
+
+func (s *ExpvarSink) Add(m Metric, delta int64) {
+	status := m.Status.String()
+	s.status.Add(m.Side+":"+status, delta)
+	if m.Reason != "" {
+		s.reason.Add(m.Side+":"+m.Domain+":"+m.Reason, delta)
+	}
+	if m.Route != "" {
+		s.route.Add(m.Side+":"+m.Route+":"+status, delta)
+	}
+}
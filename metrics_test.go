@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetric(t *testing.T) {
+	m := NewMetric(MetricServer, "/v1/cats", nil)
+	assert.Equal(t, Metric{Side: MetricServer, Route: "/v1/cats"}, m)
+
+	err := Annotate(sql.ErrNoRows, NotFound, resourceInfo, errInfo, ErrorInfo{Reason: "other"})
+	m = NewMetric(MetricClient, "", err)
+	assert.Equal(t, Metric{
+		Side:   MetricClient,
+		Status: NotFound,
+		Domain: errInfo.Domain,
+		Reason: errInfo.Reason,
+	}, m)
+}
+
+func TestExpvarSink(t *testing.T) {
+	name := fmt.Sprintf("errors_test_metrics_%d", time.Now().UnixNano())
+	sink := NewExpvarSink(name)
+	sink.Add(Metric{Side: MetricServer, Route: "/v1/cats", Status: NotFound, Domain: "pet.com", Reason: "CAT_NOT_FOUND"}, 2)
+	sink.Add(Metric{Side: MetricClient, Status: Unavailable}, 1)
+	sink.Add(Metric{Side: MetricServer, Route: "/v1/cats"}, 2)
+	NewExpvarSink(name).Add(Metric{Side: MetricServer, Route: "/v1/cats"}, 1)
+
+	var out map[string]map[string]int64
+	if !assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &out)) {
+		return
+	}
+
+	assert.Equal(t, map[string]map[string]int64{
+		"status": {
+			"server:NOT_FOUND":   2,
+			"server:OK":          3,
+			"client:UNAVAILABLE": 1,
+		},
+		"reason": {
+			"server:pet.com:CAT_NOT_FOUND": 2,
+		},
+		"route": {
+			"server:/v1/cats:NOT_FOUND": 2,
+			"server:/v1/cats:OK":        3,
+		},
+	}, out)
+}
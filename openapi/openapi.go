@@ -0,0 +1,366 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gota33/errors"
+	"github.com/gota33/errors/catalog"
+)
+
+const (
+	Version = "3.1.0"
+
+	SchemaError  = "Error"
+	SchemaStatus = "Status"
+	SchemaDetail = "Detail"
+
+	DefaultTitle   = "Errors"
+	DefaultVersion = "1.0.0"
+)
+
+var (
+	nonIdentPattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+	durationType   = reflect.TypeOf(errors.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+type Schema map[string]interface{}
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths,omitempty"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Responses map[string]*Response `json:"responses"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema   Schema            `json:"schema"`
+	Examples map[string]Schema `json:"examples,omitempty"`
+}
+
+type Components struct {
+	Schemas  map[string]Schema  `json:"schemas"`
+	Examples map[string]Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+type Generator struct {
+	Registry *errors.Registry
+	Catalogs []*catalog.Catalog
+	Mapping  errors.HttpMapping
+	Title    string
+	Version  string
+}
+
+func Generate(catalogs ...*catalog.Catalog) (*Document, error) {
+	return (&Generator{Catalogs: catalogs}).Generate()
+}
+
+func (g *Generator) Generate() (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: g.Title, Version: g.Version},
+		Components: Components{
+			Schemas: map[string]Schema{
+				SchemaError:  envelopeSchema(),
+				SchemaStatus: statusSchema(),
+			},
+		},
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = DefaultTitle
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = DefaultVersion
+	}
+
+	g.addDetails(doc)
+	if err := g.addCatalogs(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (g *Generator) addDetails(doc *Document) {
+	registry := g.Registry
+	if registry == nil {
+		registry = errors.DefaultRegistry
+	}
+
+	var (
+		oneOf   []Schema
+		mapping = make(map[string]string)
+	)
+	typeUrls := registry.TypeUrls()
+	sort.SliceStable(typeUrls, func(i, j int) bool {
+		return isBuiltin(typeUrls[i]) && !isBuiltin(typeUrls[j])
+	})
+
+	for _, typeUrl := range typeUrls {
+		detail, ok := registry.New(typeUrl)
+		if !ok {
+			continue
+		}
+
+		name := schemaName(typeUrl)
+		if _, taken := doc.Components.Schemas[name]; taken || name == SchemaDetail {
+			name = nonIdentPattern.ReplaceAllString(typeUrl, "_")
+		}
+
+		schema := typeSchema(reflect.TypeOf(detail))
+		if schema["type"] != "object" {
+			schema = Schema{"type": "object"}
+		}
+		properties, _ := schema["properties"].(map[string]Schema)
+		if properties == nil {
+			properties = make(map[string]Schema)
+		}
+		properties["@type"] = Schema{"type": "string", "const": typeUrl}
+		schema["properties"] = properties
+		schema["required"] = []string{"@type"}
+
+		doc.Components.Schemas[name] = schema
+		oneOf = append(oneOf, ref(name))
+		mapping[typeUrl] = ref(name)["$ref"].(string)
+	}
+
+	doc.Components.Schemas[SchemaDetail] = Schema{
+		"oneOf": oneOf,
+		"discriminator": Schema{
+			"propertyName": "@type",
+			"mapping":      mapping,
+		},
+	}
+}
+
+func (g *Generator) addCatalogs(doc *Document) error {
+	for _, c := range g.Catalogs {
+		for _, e := range c.Errors {
+			key := e.Reason
+			if _, taken := doc.Components.Examples[key]; taken {
+				key = nonIdentPattern.ReplaceAllString(c.Domain, "_") + "." + e.Reason
+			}
+
+			value, err := g.example(c, e)
+			if err != nil {
+				return fmt.Errorf("openapi: %s: %w", e.Reason, err)
+			}
+			if doc.Components.Examples == nil {
+				doc.Components.Examples = make(map[string]Example)
+			}
+			doc.Components.Examples[key] = Example{Summary: e.Description, Value: value}
+
+			for _, route := range e.Routes {
+				method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+				path = strings.TrimSpace(path)
+				if !ok || path == "" {
+					return fmt.Errorf("openapi: %s: invalid route %q, want \"METHOD /path\"", e.Reason, route)
+				}
+				g.addResponse(doc, strings.ToLower(method), path, e, key)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) addResponse(doc *Document, method, path string, e catalog.Entry, example string) {
+	if doc.Paths == nil {
+		doc.Paths = make(map[string]PathItem)
+	}
+	item := doc.Paths[path]
+	if item == nil {
+		item = make(PathItem)
+		doc.Paths[path] = item
+	}
+	op := item[method]
+	if op == nil {
+		op = &Operation{Responses: make(map[string]*Response)}
+		item[method] = op
+	}
+
+	status := g.Mapping.Http(e.Code())
+	resp := op.Responses[strconv.Itoa(status)]
+	if resp == nil {
+		resp = &Response{
+			Description: http.StatusText(status),
+			Content: map[string]MediaType{
+				"application/json": {Schema: ref(SchemaError), Examples: make(map[string]Schema)},
+			},
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	resp.Content["application/json"].Examples[example] = Schema{"$ref": "#/components/examples/" + example}
+}
+
+func (g *Generator) example(c *catalog.Catalog, e catalog.Entry) (value interface{}, err error) {
+	metadata := make(map[string]string, len(e.Metadata))
+	for _, key := range e.Metadata {
+		metadata[key] = "{" + key + "}"
+	}
+
+	annotations := []errors.Annotation{
+		e.Code(),
+		errors.ErrorInfo{Reason: e.Reason, Domain: c.Domain, Metadata: metadata},
+	}
+	for _, local := range e.Locales() {
+		annotations = append(annotations, errors.LocalizedMessage{Local: local, Message: e.Localized[local]})
+	}
+	if len(e.Help) > 0 {
+		links := make([]errors.Link, len(e.Help))
+		for i, link := range e.Help {
+			links[i] = errors.Link{Description: link.Description, Url: link.Url}
+		}
+		annotations = append(annotations, errors.Help{Links: links})
+	}
+
+	var buf bytes.Buffer
+	enc := errors.NewEncoder(json.NewEncoder(&buf))
+	enc.Mapping = g.Mapping
+	if err = enc.Encode(errors.Annotate(errors.New(e.Message), annotations...)); err != nil {
+		return
+	}
+	err = json.Unmarshal(buf.Bytes(), &value)
+	return
+}
+
+func envelopeSchema() Schema {
+	return Schema{
+		"type":       "object",
+		"required":   []string{"error"},
+		"properties": map[string]Schema{"error": ref(SchemaStatus)},
+	}
+}
+
+func statusSchema() Schema {
+	var names []string
+	for code := errors.StatusCode(1); code.Valid(); code++ {
+		names = append(names, code.String())
+	}
+
+	return Schema{
+		"type": "object",
+		"properties": map[string]Schema{
+			"code":    {"type": "integer", "format": "int32", "description": "HTTP status code"},
+			"message": {"type": "string"},
+			"status":  {"type": "string", "enum": names},
+			"details": {"type": "array", "items": ref(SchemaDetail)},
+		},
+	}
+}
+
+func typeSchema(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		return Schema{"type": "string", "format": "duration", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`}
+	case t == rawMessageType:
+		return Schema{}
+	case t.Kind() != reflect.Struct && t.Kind() != reflect.Map && t.Implements(marshalerType):
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return Schema{"type": "object"}
+		}
+		return Schema{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]Schema)
+		addFields(properties, t)
+		return Schema{"type": "object", "properties": properties}
+	}
+	return Schema{}
+}
+
+func addFields(properties map[string]Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(properties, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = typeSchema(f.Type)
+	}
+}
+
+func schemaName(typeUrl string) string {
+	name := typeUrl[strings.LastIndexAny(typeUrl, "/.")+1:]
+	if name == "" {
+		return nonIdentPattern.ReplaceAllString(typeUrl, "_")
+	}
+	return name
+}
+
+func isBuiltin(typeUrl string) bool {
+	return strings.HasPrefix(typeUrl, "type.googleapis.com/google.rpc.")
+}
+
+func ref(name string) Schema {
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
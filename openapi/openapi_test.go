@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gota33/errors"
+	"github.com/gota33/errors/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+type quotaDetail struct {
+	Limit   int               `json:"limit"`
+	Window  errors.Duration   `json:"window"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Raw     json.RawMessage   `json:"raw,omitempty"`
+	Ignored string            `json:"-"`
+	hidden  string
+	nested
+}
+
+type nested struct {
+	Ratio float64 `json:"ratio"`
+}
+
+func (d quotaDetail) TypeUrl() string            { return "example.com/ErrorInfo" }
+func (d quotaDetail) Annotate(m errors.Modifier) { m.AppendDetails(d) }
+
+func (d quotaDetail) MarshalJSON() ([]byte, error) {
+	type payload quotaDetail
+	return json.Marshal(struct {
+		Type string `json:"@type"`
+		payload
+	}{d.TypeUrl(), payload(d)})
+}
+
+func TestGenerate(t *testing.T) {
+	c, err := catalog.Load("../catalog/testdata/pets.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	doc, err := Generate(c)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Equal(t, Info{Title: DefaultTitle, Version: DefaultVersion}, doc.Info)
+
+	schemas := doc.Components.Schemas
+	mapping := schemas[SchemaDetail]["discriminator"].(Schema)["mapping"].(map[string]string)
+	assert.Len(t, mapping, len(errors.DefaultRegistry.TypeUrls()))
+	assert.Equal(t, "#/components/schemas/RetryInfo", mapping[errors.TypeUrlRetryInfo])
+
+	retry := schemas["RetryInfo"]["properties"].(map[string]Schema)
+	assert.Equal(t, "duration", retry["retryDelay"]["format"])
+
+	op := doc.Paths["/v1/cats/{name}"]["get"]
+	if assert.NotNil(t, op) {
+		resp := op.Responses["404"]
+		assert.Equal(t, "Not Found", resp.Description)
+		assert.Equal(t, ref(SchemaError), resp.Content["application/json"].Schema)
+		assert.Equal(t, Schema{"$ref": "#/components/examples/CAT_NOT_FOUND"}, resp.Content["application/json"].Examples["CAT_NOT_FOUND"])
+	}
+	assert.NotNil(t, doc.Paths["/v1/cats"]["post"].Responses["429"])
+
+	// Every detail in every example must be described by its schema.
+	for name, example := range doc.Components.Examples {
+		envelope := example.Value.(map[string]interface{})["error"].(map[string]interface{})
+		for _, d := range envelope["details"].([]interface{}) {
+			detail := d.(map[string]interface{})
+			target := mapping[detail["@type"].(string)]
+			if !assert.NotEmpty(t, target, name) {
+				continue
+			}
+			properties := schemas[target[len("#/components/schemas/"):]]["properties"].(map[string]Schema)
+			for key := range detail {
+				assert.Contains(t, properties, key, "%s: %s", name, target)
+			}
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"openapi":"3.1.0"`)
+}
+
+func TestGenerateRegistry(t *testing.T) {
+	registry := errors.NewRegistry()
+	assert.NoError(t, errors.RegisterType[quotaDetail](registry))
+
+	doc, err := (&Generator{Registry: registry, Title: "Pets", Version: "2"}).Generate()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Info{Title: "Pets", Version: "2"}, doc.Info)
+
+	schema, ok := doc.Components.Schemas["example.com_ErrorInfo"]
+	if assert.True(t, ok) {
+		assert.Equal(t, Schema{
+			"type":     "object",
+			"required": []string{"@type"},
+			"properties": map[string]Schema{
+				"@type":  {"type": "string", "const": "example.com/ErrorInfo"},
+				"limit":  {"type": "integer"},
+				"window": {"type": "string", "format": "duration", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`},
+				"labels": {"type": "object", "additionalProperties": Schema{"type": "string"}},
+				"raw":    {},
+				"ratio":  {"type": "number"},
+			},
+		}, schema)
+	}
+	assert.Equal(t, errors.TypeUrlErrorInfo, doc.Components.Schemas["ErrorInfo"]["properties"].(map[string]Schema)["@type"]["const"])
+}
+
+func TestGenerateCatalogs(t *testing.T) {
+	entry := catalog.Entry{Reason: "NOT_READY", Status: "UNAVAILABLE", Message: "not ready", Routes: []string{"GET /ready"}}
+	a := &catalog.Catalog{Domain: "a.example.com", Errors: []catalog.Entry{entry}}
+	b := &catalog.Catalog{Domain: "b.example.com", Errors: []catalog.Entry{entry}}
+
+	doc, err := Generate(a, b)
+	if assert.NoError(t, err) {
+		assert.Contains(t, doc.Components.Examples, "NOT_READY")
+		assert.Contains(t, doc.Components.Examples, "b.example.com.NOT_READY")
+		assert.Len(t, doc.Paths["/ready"]["get"].Responses["503"].Content["application/json"].Examples, 2)
+	}
+
+	entry.Routes = []string{"/ready"}
+	_, err = Generate(&catalog.Catalog{Domain: "a.example.com", Errors: []catalog.Entry{entry}})
+	assert.ErrorContains(t, err, `invalid route "/ready"`)
+}
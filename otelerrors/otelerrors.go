@@ -0,0 +1,73 @@
+package otelerrors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gota33/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var keys = map[string]attribute.Key{
+	errors.AttrErrorStatus:    "error.type",
+	errors.AttrHttpStatusCode: "http.response.status_code",
+}
+
+func Tracer() errors.Tracer {
+	return errors.TracerFunc(func(ctx context.Context, t errors.Trace) {
+		Apply(trace.SpanFromContext(ctx), t)
+	})
+}
+
+func Apply(span trace.Span, t errors.Trace) {
+	if !span.IsRecording() {
+		return
+	}
+
+	span.SetAttributes(Attributes(t.Attributes)...)
+	for _, event := range t.Events {
+		span.AddEvent(event.Name, trace.WithAttributes(Attributes(event.Attributes)...))
+	}
+
+	if t.Status == errors.SpanError {
+		span.SetStatus(codes.Error, t.Description)
+	}
+}
+
+func Attributes(attrs []errors.TraceAttribute) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		key, ok := keys[a.Key]
+		if !ok {
+			key = attribute.Key(a.Key)
+		}
+		out = append(out, KeyValue(key, a.Value))
+	}
+	return out
+}
+
+func KeyValue(key attribute.Key, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return key.String(v)
+	case int:
+		return key.Int(v)
+	case int64:
+		return key.Int64(v)
+	case uint64:
+		return key.Int64(int64(v))
+	case float64:
+		return key.Float64(v)
+	case bool:
+		return key.Bool(v)
+	case time.Duration:
+		return key.String(v.String())
+	case []string:
+		return key.StringSlice(v)
+	default:
+		return key.String(fmt.Sprint(v))
+	}
+}
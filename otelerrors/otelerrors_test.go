@@ -0,0 +1,59 @@
+package otelerrors
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gota33/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	err := errors.Annotate(sql.ErrNoRows,
+		errors.NotFound,
+		errors.ErrorInfo{Reason: "CAT_NOT_FOUND", Domain: "pet.com"},
+		errors.RetryInfo{RetryDelay: errors.Duration(time.Second)},
+	)
+	Tracer().TraceError(ctx, errors.NewTrace(err))
+	span.End()
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	s := spans[0]
+	assert.Equal(t, codes.Error, s.Status().Code)
+	assert.Equal(t, sql.ErrNoRows.Error(), s.Status().Description)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("error.type", "NOT_FOUND"),
+		attribute.Int("http.response.status_code", 404),
+		attribute.String(errors.AttrErrorReason, "CAT_NOT_FOUND"),
+		attribute.String(errors.AttrErrorDomain, "pet.com"),
+	}, s.Attributes())
+
+	if assert.Len(t, s.Events(), 2) {
+		assert.Equal(t, errors.TypeUrlRetryInfo, s.Events()[1].Name)
+		assert.Equal(t, []attribute.KeyValue{attribute.String("retry_delay", "1s")}, s.Events()[1].Attributes)
+	}
+}
+
+func TestKeyValue(t *testing.T) {
+	key := attribute.Key("k")
+	assert.Equal(t, key.Int64(1), KeyValue(key, int64(1)))
+	assert.Equal(t, key.Int64(2), KeyValue(key, uint64(2)))
+	assert.Equal(t, key.Float64(1.5), KeyValue(key, 1.5))
+	assert.Equal(t, key.Bool(true), KeyValue(key, true))
+	assert.Equal(t, key.StringSlice([]string{"a"}), KeyValue(key, []string{"a"}))
+	assert.Equal(t, key.String("[1 2]"), KeyValue(key, []int{1, 2}))
+}
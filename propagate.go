@@ -0,0 +1,49 @@
+package errors
+
+type Policy struct {
+	Remap       map[StatusCode]StatusCode
+	Message     string
+	DropDetails bool
+	Keep        []string
+}
+
+func Propagate(err error, policy Policy) error {
+	if err == nil {
+		return nil
+	}
+
+	code := Code(err)
+	if mapped, ok := policy.Remap[code]; ok {
+		code = mapped
+	}
+
+	a := annotated{
+		cause:   err,
+		code:    code,
+		message: err.Error(),
+	}
+	if policy.Message != "" {
+		a.message = policy.Message
+	}
+
+	if !policy.DropDetails {
+		return &a
+	}
+
+	keep := make(map[string]bool, len(policy.Keep))
+	for _, typeUrl := range policy.Keep {
+		keep[typeUrl] = true
+	}
+	for _, detail := range Details(err) {
+		if keep[detail.TypeUrl()] {
+			a.details = append(a.details, detail)
+		}
+	}
+	return &propagated{a}
+}
+
+// propagated stops code and detail lookups from reaching the upstream error,
+// which is still available to errors.Is, errors.As and Unwrap.
+type propagated struct {
+	annotated
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropagate(t *testing.T) {
+	upstream := Annotate(sql.ErrNoRows, Unauthenticated, errInfo, retryInfo, debugInfo)
+
+	t.Run("nil", func(t *testing.T) {
+		assert.NoError(t, Propagate(nil, Policy{}))
+	})
+
+	t.Run("remap", func(t *testing.T) {
+		err := Propagate(upstream, Policy{
+			Remap: map[StatusCode]StatusCode{Unauthenticated: Internal},
+		})
+
+		assert.Equal(t, Internal, Code(err))
+		assert.Equal(t, upstream.Error(), err.Error())
+		assert.Equal(t, []Any{errInfo, retryInfo, debugInfo}, Details(err))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Equal(t, upstream, Unwrap(err))
+	})
+
+	t.Run("keep code", func(t *testing.T) {
+		err := Propagate(upstream, Policy{
+			Remap: map[StatusCode]StatusCode{NotFound: Internal},
+		})
+		assert.Equal(t, Unauthenticated, Code(err))
+	})
+
+	t.Run("drop details", func(t *testing.T) {
+		err := Propagate(upstream, Policy{
+			Remap:       map[StatusCode]StatusCode{Unauthenticated: Internal},
+			Message:     "upstream failure",
+			DropDetails: true,
+			Keep:        []string{TypeUrlRetryInfo},
+		})
+
+		assert.Equal(t, Internal, Code(err))
+		assert.Equal(t, "upstream failure", err.Error())
+		assert.Equal(t, []Any{retryInfo}, Details(err))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		flat := Flatten(err).(*annotated)
+		assert.Equal(t, Internal, flat.code)
+		assert.Equal(t, "upstream failure", flat.message)
+		assert.Equal(t, []Any{retryInfo}, flat.details)
+
+		str := fmt.Sprintf("%+v", err)
+		assert.Contains(t, str, `status: "500 INTERNAL"`)
+		assert.NotContains(t, str, "UNAUTHENTICATED")
+	})
+}
@@ -0,0 +1,157 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrInvalidType = errors.New("registry: invalid detail type")
+
+var builtinTypes = map[string]registration{
+	TypeUrlRetryInfo:           valueOf[RetryInfo](),
+	TypeUrlDebugInfo:           valueOf[DebugInfo](),
+	TypeUrlResourceInfo:        valueOf[ResourceInfo](),
+	TypeUrlBadRequest:          valueOf[BadRequest](),
+	TypeUrlPreconditionFailure: valueOf[PreconditionFailure](),
+	TypeUrlErrorInfo:           valueOf[ErrorInfo](),
+	TypeUrlQuotaFailure:        valueOf[QuotaFailure](),
+	TypeUrlRequestInfo:         valueOf[RequestInfo](),
+	TypeUrlHelp:                valueOf[Help](),
+	TypeUrlLocalizedMessage:    valueOf[LocalizedMessage](),
+}
+
+var DefaultRegistry = NewRegistry()
+
+func Register(typeUrl string, provider func() Any) {
+	DefaultRegistry.Register(typeUrl, provider)
+}
+
+func RegisterType[T Any](registry ...*Registry) (err error) {
+	typeUrl, err := validateType[T]()
+	if err != nil {
+		return
+	}
+
+	r := DefaultRegistry
+	if len(registry) > 0 {
+		r = registry[0]
+	}
+	r.register(typeUrl, valueOf[T]())
+	return
+}
+
+type registration struct {
+	target func() interface{}
+	value  func(target interface{}) Any
+}
+
+func valueOf[T Any]() registration {
+	return registration{
+		target: func() interface{} { return new(T) },
+		value:  func(target interface{}) Any { return *target.(*T) },
+	}
+}
+
+func providerOf(provider func() Any) registration {
+	return registration{
+		target: func() interface{} { return provider() },
+		value:  func(target interface{}) Any { return target.(Any) },
+	}
+}
+
+func validateType[T Any]() (typeUrl string, err error) {
+	var zero T
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w %T: %v", ErrInvalidType, zero, r)
+		}
+	}()
+
+	if typeUrl = zero.TypeUrl(); typeUrl == "" {
+		return "", fmt.Errorf("%w %T: empty type url", ErrInvalidType, zero)
+	}
+
+	data, err := json.Marshal(zero)
+	if err != nil {
+		return "", fmt.Errorf("%w %T: %s", ErrInvalidType, zero, err)
+	}
+
+	var w struct {
+		Type string `json:"@type"`
+	}
+	if err = json.Unmarshal(data, &w); err != nil || w.Type != typeUrl {
+		return "", fmt.Errorf("%w %T: encoded @type %q mismatch %q", ErrInvalidType, zero, w.Type, typeUrl)
+	}
+
+	var out T
+	if err = json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("%w %T: %s", ErrInvalidType, zero, err)
+	}
+
+	again, err := json.Marshal(out)
+	if err != nil || !bytes.Equal(data, again) {
+		return "", fmt.Errorf("%w %T: unstable json %s != %s", ErrInvalidType, zero, data, again)
+	}
+	return
+}
+
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]registration
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{types: make(map[string]registration, len(builtinTypes))}
+	for typeUrl, reg := range builtinTypes {
+		r.types[typeUrl] = reg
+	}
+	return r
+}
+
+func (r *Registry) Register(typeUrl string, provider func() Any) {
+	if provider == nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.types, typeUrl)
+		return
+	}
+	r.register(typeUrl, providerOf(provider))
+}
+
+func (r *Registry) register(typeUrl string, reg registration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.types[typeUrl] = reg
+}
+
+func (r *Registry) New(typeUrl string) (detail Any, ok bool) {
+	if reg, ok := r.lookup(typeUrl); ok {
+		return reg.value(reg.target()), true
+	}
+	return nil, false
+}
+
+func (r *Registry) lookup(typeUrl string) (reg registration, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reg, ok = r.types[typeUrl]
+	return
+}
+
+func (r *Registry) TypeUrls() (out []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out = make([]string, 0, len(r.types))
+	for typeUrl := range r.types {
+		out = append(out, typeUrl)
+	}
+	sort.Strings(out)
+	return
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type customDetail struct {
+	Name string `json:"name,omitempty"`
+}
+
+func (d customDetail) TypeUrl() string     { return typeUrlCustom }
+func (d customDetail) Annotate(m Modifier) { m.AppendDetails(d) }
+
+func (d customDetail) MarshalJSON() ([]byte, error) {
+	type payload customDetail
+	return json.Marshal(struct {
+		Type string `json:"@type"`
+		payload
+	}{d.TypeUrl(), payload(d)})
+}
+
+type untypedDetail struct {
+	Name string `json:"name,omitempty"`
+}
+
+func (d untypedDetail) TypeUrl() string     { return typeUrlCustom }
+func (d untypedDetail) Annotate(m Modifier) { m.AppendDetails(d) }
+
+func TestCustomType(t *testing.T) {
+	Register(typeUrlCustom, func() Any { return new(AnyDetail) })
+	_, ok := DefaultRegistry.New(typeUrlCustom)
+	assert.True(t, ok)
+
+	Register(typeUrlCustom, nil)
+	_, ok = DefaultRegistry.New(typeUrlCustom)
+	assert.False(t, ok)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("builtin", func(t *testing.T) {
+		r := NewRegistry()
+		for typeUrl := range builtinTypes {
+			detail, ok := r.New(typeUrl)
+			if assert.True(t, ok, typeUrl) {
+				assert.Equal(t, typeUrl, detail.TypeUrl())
+			}
+		}
+		assert.Len(t, r.TypeUrls(), len(builtinTypes))
+	})
+
+	t.Run("isolated", func(t *testing.T) {
+		const raw = `{"error":{"status":"INTERNAL","details":[{"@type":"custom/type","1":"2"}]}}`
+
+		r := NewRegistry()
+		r.Register(typeUrlCustom, func() Any { return new(AnyDetail) })
+
+		_, ok := DefaultRegistry.New(typeUrlCustom)
+		assert.False(t, ok)
+
+		err := NewDecoder(json.NewDecoder(strings.NewReader(raw)), r).Decode()
+		assert.IsType(t, &AnyDetail{}, Details(err)[0])
+
+		err = NewDecoder(json.NewDecoder(strings.NewReader(raw))).Decode()
+		assert.IsType(t, RawDetail{}, Details(err)[0])
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		r := NewRegistry()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				r.Register(typeUrlCustom, func() Any { return new(AnyDetail) })
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = r.New(typeUrlCustom)
+				_ = r.TypeUrls()
+			}()
+		}
+		wg.Wait()
+
+		_, ok := r.New(typeUrlCustom)
+		assert.True(t, ok)
+	})
+}
+
+func TestRegisterType(t *testing.T) {
+	t.Run("value", func(t *testing.T) {
+		const raw = `{"error":{"status":"INTERNAL","details":[{"@type":"custom/type","name":"1"}]}}`
+
+		r := NewRegistry()
+		if !assert.NoError(t, RegisterType[customDetail](r)) {
+			return
+		}
+
+		err := NewDecoder(json.NewDecoder(strings.NewReader(raw)), r).Decode()
+		assert.Equal(t, []Any{customDetail{Name: "1"}}, Details(err))
+	})
+
+	t.Run("builtin", func(t *testing.T) {
+		err := NewDecoder(json.NewDecoder(strings.NewReader(rawFullError))).Decode()
+		decoded := Details(err)
+		if assert.Len(t, decoded, len(fullError.details)) {
+			assert.Equal(t, fullError.details[:10], decoded[:10])
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		r := NewRegistry()
+		assert.ErrorIs(t, RegisterType[untypedDetail](r), ErrInvalidType)
+		assert.ErrorIs(t, RegisterType[*customDetail](r), ErrInvalidType)
+		assert.Len(t, r.TypeUrls(), len(builtinTypes))
+	})
+}
@@ -0,0 +1,321 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultReportWindow    = time.Minute
+	DefaultReportBatchSize = 100
+)
+
+type Report struct {
+	Fingerprint string
+	Source      string
+	Error       error
+	Count       int
+	First       time.Time
+	Last        time.Time
+}
+
+func (r Report) MarshalJSON() ([]byte, error) {
+	var body messageBody
+	if a, ok := Flatten(r.Error).(*annotated); ok {
+		body.Code = a.code.Http()
+		body.Status = a.code.Name()
+		body.Message = a.message
+		body.Details = a.details
+	}
+
+	return json.Marshal(struct {
+		Fingerprint string      `json:"fingerprint"`
+		Source      string      `json:"source,omitempty"`
+		Count       int         `json:"count"`
+		First       time.Time   `json:"first"`
+		Last        time.Time   `json:"last"`
+		Error       messageBody `json:"error"`
+	}{r.Fingerprint, r.Source, r.Count, r.First, r.Last, body})
+}
+
+type ReportSink interface {
+	Write(reports []Report) error
+}
+
+type Reporter struct {
+	Sinks         []ReportSink
+	Window        time.Duration
+	BatchSize     int
+	Sampling      map[StatusCode]float64
+	Mappers       []DetailMapper
+	Fingerprinter Fingerprinter
+
+	mu      sync.Mutex
+	seen    map[string]*reportEntry
+	pending []*Report
+	now     func() time.Time
+	random  func() float64
+}
+
+type reportEntry struct {
+	start      time.Time
+	report     *Report
+	pending    bool
+	suppressed int
+	since      time.Time
+	last       time.Time
+}
+
+func NewReporter(sinks ...ReportSink) *Reporter {
+	return &Reporter{Sinks: sinks}
+}
+
+func (r *Reporter) Report(source string, err error) {
+	if err == nil || !r.sample(Code(err)) {
+		return
+	}
+
+	fingerprint := r.Fingerprinter.Fingerprint(err)
+	now := r.clock()
+
+	r.mu.Lock()
+	if r.seen == nil {
+		r.seen = make(map[string]*reportEntry)
+	}
+
+	e, ok := r.seen[fingerprint]
+	if ok && now.Sub(e.start) < r.window() {
+		if e.pending {
+			e.report.Count++
+			e.report.Last = now
+		} else {
+			if e.suppressed == 0 {
+				e.since = now
+			}
+			e.suppressed++
+			e.last = now
+		}
+		r.mu.Unlock()
+		return
+	}
+
+	report := &Report{
+		Fingerprint: fingerprint,
+		Source:      source,
+		Error:       Flatten(err, r.Mappers...),
+		Count:       1,
+		First:       now,
+		Last:        now,
+	}
+	if ok && e.suppressed > 0 {
+		report.Count += e.suppressed
+		report.First = e.since
+	}
+	r.seen[fingerprint] = &reportEntry{start: now, report: report, pending: true}
+	r.pending = append(r.pending, report)
+
+	full := len(r.pending) >= r.batchSize()
+	r.mu.Unlock()
+
+	if full {
+		_ = r.Flush()
+	}
+}
+
+func (r *Reporter) Flush() error {
+	r.mu.Lock()
+	r.prune()
+	batch := make([]Report, len(r.pending))
+	for i, report := range r.pending {
+		batch[i] = *report
+		if e, ok := r.seen[report.Fingerprint]; ok && e.report == report {
+			e.pending = false
+		}
+	}
+	r.pending = nil
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, sink := range r.Sinks {
+		if err := sink.Write(batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Reporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = r.Flush()
+			return
+		case <-ticker.C:
+			_ = r.Flush()
+		}
+	}
+}
+
+func (r *Reporter) Recover(source string) {
+	if v := recover(); v != nil {
+		r.Report(source, PanicError(v))
+	}
+}
+
+func (r *Reporter) Go(source string, job func() error) {
+	go func() {
+		defer r.Recover(source)
+		r.Report(source, job())
+	}()
+}
+
+// prune drops expired entries, queueing a follow-up report for occurrences
+// suppressed since their last report.
+func (r *Reporter) prune() {
+	now := r.clock()
+	for fingerprint, e := range r.seen {
+		if e.pending || now.Sub(e.start) < r.window() {
+			continue
+		}
+		if e.suppressed > 0 {
+			r.pending = append(r.pending, &Report{
+				Fingerprint: fingerprint,
+				Source:      e.report.Source,
+				Error:       e.report.Error,
+				Count:       e.suppressed,
+				First:       e.since,
+				Last:        e.last,
+			})
+		}
+		delete(r.seen, fingerprint)
+	}
+}
+
+func (r *Reporter) sample(code StatusCode) bool {
+	rate, ok := r.Sampling[code]
+	if !ok || rate >= 1 {
+		return true
+	}
+	if r.random != nil {
+		return r.random() < rate
+	}
+	return rand.Float64() < rate
+}
+
+func (r *Reporter) window() time.Duration {
+	if r.Window > 0 {
+		return r.Window
+	}
+	return DefaultReportWindow
+}
+
+func (r *Reporter) batchSize() int {
+	if r.BatchSize > 0 {
+		return r.BatchSize
+	}
+	return DefaultReportBatchSize
+}
+
+func (r *Reporter) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+func PanicError(v interface{}) error {
+	err, ok := v.(error)
+	if ok {
+		err = fmt.Errorf("panic: %w", err)
+	} else {
+		err = fmt.Errorf("panic: %v", v)
+	}
+	return Annotate(err, Internal, StackTrace("panic"))
+}
+
+type NDJSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewNDJSONSink(w io.Writer) *NDJSONSink {
+	return &NDJSONSink{w: w}
+}
+
+func OpenNDJSONFile(name string) (*NDJSONSink, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewNDJSONSink(f), nil
+}
+
+func (s *NDJSONSink) Write(reports []Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := json.NewEncoder(s.w)
+	for _, report := range reports {
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *NDJSONSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type RingSink struct {
+	mu      sync.Mutex
+	reports []Report
+	next    int
+	full    bool
+}
+
+func NewRingSink(capacity int) *RingSink {
+	return &RingSink{reports: make([]Report, capacity)}
+}
+
+func (s *RingSink) Write(reports []Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.reports) == 0 {
+		return nil
+	}
+	for _, report := range reports {
+		s.reports[s.next] = report
+		s.next = (s.next + 1) % len(s.reports)
+		s.full = s.full || s.next == 0
+	}
+	return nil
+}
+
+func (s *RingSink) Reports() []Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.full {
+		return append([]Report(nil), s.reports[:s.next]...)
+	}
+	return append(append([]Report(nil), s.reports[s.next:]...), s.reports[:s.next]...)
+}
@@ -0,0 +1,269 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ring := NewRingSink(10)
+	r := NewReporter(ring)
+	r.Window = time.Minute
+	r.now = func() time.Time { return now }
+
+	notFound := func(id string) error {
+		return Annotate(sql.ErrNoRows, NotFound, Message("cat "+id), RequestInfo{RequestId: id})
+	}
+
+	r.Report("api", nil)
+	r.Report("api", notFound("1"))
+	now = now.Add(time.Second)
+	r.Report("api", notFound("2"))
+	r.Report("job", Internal)
+
+	assert.NoError(t, r.Flush())
+	reports := ring.Reports()
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, "api", reports[0].Source)
+		assert.Equal(t, 2, reports[0].Count)
+		assert.Equal(t, now.Add(-time.Second), reports[0].First)
+		assert.Equal(t, now, reports[0].Last)
+		assert.Equal(t, NotFound, Code(reports[0].Error))
+		assert.Equal(t, Fingerprint(notFound("1")), reports[0].Fingerprint)
+		assert.Equal(t, 1, reports[1].Count)
+	}
+
+	now = now.Add(10 * time.Second)
+	r.Report("api", notFound("3"))
+	r.Report("api", notFound("4"))
+	assert.NoError(t, r.Flush())
+	assert.Len(t, ring.Reports(), 2)
+
+	now = now.Add(time.Minute)
+	r.Report("api", notFound("5"))
+	assert.NoError(t, r.Flush())
+
+	reports = ring.Reports()
+	if assert.Len(t, reports, 3) {
+		assert.Equal(t, 3, reports[2].Count)
+		assert.Equal(t, now.Add(-time.Minute), reports[2].First)
+	}
+}
+
+func TestReporterSuppressed(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := now
+	ring := NewRingSink(10)
+	r := NewReporter(ring)
+	r.Window = time.Minute
+	r.now = func() time.Time { return now }
+
+	r.Report("job", Unavailable)
+	assert.NoError(t, r.Flush())
+	for i := 0; i < 9; i++ {
+		now = now.Add(time.Second)
+		r.Report("job", Unavailable)
+	}
+	assert.NoError(t, r.Flush())
+	assert.Len(t, ring.Reports(), 1)
+
+	now = start.Add(time.Minute)
+	assert.NoError(t, r.Flush())
+
+	reports := ring.Reports()
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, 9, reports[1].Count)
+		assert.Equal(t, "job", reports[1].Source)
+		assert.Equal(t, Unavailable, Code(reports[1].Error))
+		assert.Equal(t, start.Add(time.Second), reports[1].First)
+		assert.Equal(t, start.Add(9*time.Second), reports[1].Last)
+	}
+	assert.Empty(t, r.seen)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, r.Flush())
+	assert.Len(t, ring.Reports(), 2)
+}
+
+func TestReporterSampling(t *testing.T) {
+	ring := NewRingSink(10)
+	r := NewReporter(ring)
+	r.Sampling = map[StatusCode]float64{NotFound: 0.5, Internal: 1}
+
+	values := []float64{0.7, 0.2}
+	r.random = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+
+	r.Report("api", NotFound)
+	assert.NoError(t, r.Flush())
+	assert.Empty(t, ring.Reports())
+
+	r.Report("api", NotFound)
+	r.Report("api", Internal)
+	assert.NoError(t, r.Flush())
+	assert.Len(t, ring.Reports(), 2)
+}
+
+func TestReporterBatch(t *testing.T) {
+	ring := NewRingSink(2)
+	r := NewReporter(ring)
+	r.BatchSize = 2
+
+	r.Report("a", Internal)
+	assert.Empty(t, ring.Reports())
+	r.Report("b", NotFound)
+	assert.Len(t, ring.Reports(), 2)
+
+	r.Report("c", Unavailable)
+	r.Report("d", Aborted)
+	r.Report("e", DataLoss)
+	assert.NoError(t, r.Flush())
+
+	reports := ring.Reports()
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, "d", reports[0].Source)
+		assert.Equal(t, "e", reports[1].Source)
+	}
+}
+
+func TestReporterRecover(t *testing.T) {
+	ring := NewRingSink(10)
+	r := NewReporter(ring)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	r.Go("job", func() error {
+		defer wg.Done()
+		return Unavailable
+	})
+	r.Go("job", func() error {
+		defer wg.Done()
+		panic("boom")
+	})
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		_ = r.Flush()
+		return len(ring.Reports()) == 2
+	}, time.Second, time.Millisecond)
+
+	var panicked bool
+	for _, report := range ring.Reports() {
+		if Code(report.Error) == Internal {
+			panicked = true
+			assert.Equal(t, "panic: boom", report.Error.Error())
+		}
+	}
+	assert.True(t, panicked)
+}
+
+func TestReporterRun(t *testing.T) {
+	ring := NewRingSink(10)
+	r := NewReporter(ring)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	r.Report("job", Internal)
+	assert.Eventually(t, func() bool { return len(ring.Reports()) == 1 }, time.Second, time.Millisecond)
+
+	r.Report("job", Unavailable)
+	cancel()
+	<-done
+	assert.Len(t, ring.Reports(), 2)
+}
+
+func TestReporterHandler(t *testing.T) {
+	ring := NewRingSink(10)
+	r := NewReporter(ring)
+
+	h := &Handler{
+		Route:    "/v1/cats",
+		Reporter: r,
+		Mappers:  []DetailMapper{HideDebugInfo},
+		Handle: func(w http.ResponseWriter, r *http.Request) error {
+			panic(sql.ErrConnDone)
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/cats", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":500,"message":"panic: sql: connection is already closed","status":"INTERNAL"}}`, rec.Body.String())
+
+	assert.NoError(t, r.Flush())
+	reports := ring.Reports()
+	if assert.Len(t, reports, 1) {
+		assert.ErrorIs(t, reports[0].Error, sql.ErrConnDone)
+		assert.Equal(t, "/v1/cats", reports[0].Source)
+	}
+
+	h.Handle = func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	}
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/cats", nil))
+	})
+}
+
+func TestNDJSONSink(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := Report{
+		Fingerprint: "abc",
+		Source:      "api",
+		Error:       Annotate(sql.ErrNoRows, NotFound, resourceInfo),
+		Count:       2,
+		First:       at,
+		Last:        at,
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewNDJSONSink(&buf).Write([]Report{report, report}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.JSONEq(t, `{"fingerprint":"abc","source":"api","count":2,"first":"2024-01-01T00:00:00Z","last":"2024-01-01T00:00:00Z",`+
+			`"error":{"code":404,"message":"sql: no rows in result set","status":"NOT_FOUND","details":[`+details[2].Json+`]}}`, lines[0])
+	}
+
+	name := filepath.Join(t.TempDir(), "errors.ndjson")
+	for i := 0; i < 2; i++ {
+		sink, err := OpenNDJSONFile(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, sink.Write([]Report{report}))
+		assert.NoError(t, sink.Close())
+	}
+
+	data, err := os.ReadFile(name)
+	if assert.NoError(t, err) {
+		var decoded map[string]interface{}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		assert.Len(t, lines, 2)
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &decoded))
+	}
+
+	_, err = OpenNDJSONFile(filepath.Join(t.TempDir(), "missing", "errors.ndjson"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = 100 * time.Millisecond
+	DefaultRetryMaxDelay  = 10 * time.Second
+)
+
+var DefaultRetryCodes = []StatusCode{Unavailable, ResourceExhausted, Aborted}
+
+type RetryTransport struct {
+	Parent      http.RoundTripper
+	MaxAttempts int
+	Codes       []StatusCode
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	ctx := req.Context()
+	attempt := 1
+	for ; ; attempt++ {
+		if resp, err = t.next(req); err == nil {
+			return
+		}
+
+		if attempt >= t.maxAttempts() || ctx.Err() != nil || !t.retryable(err) {
+			break
+		}
+
+		delay, ok := t.delay(err, attempt)
+		if !ok {
+			break
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			break
+		}
+
+		next, ok := rewind(req)
+		if !ok {
+			break
+		}
+
+		if resp != nil {
+			drain(resp)
+			resp = nil
+		}
+		if info, ok := ResponseInfo(err); ok && info.Response != nil {
+			drain(info.Response)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, retried(err, attempt)
+		case <-timer.C:
+		}
+		req = next
+	}
+
+	return resp, retried(err, attempt)
+}
+
+func (t *RetryTransport) next(req *http.Request) (*http.Response, error) {
+	rt := t.Parent
+	if rt == nil {
+		rt = &RoundTripper{}
+	}
+	return rt.RoundTrip(req)
+}
+
+func (t *RetryTransport) retryable(err error) bool {
+	if Temporary(err) {
+		return true
+	}
+
+	codes := t.Codes
+	if codes == nil {
+		codes = DefaultRetryCodes
+	}
+
+	code := Code(err)
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay reports false when the server asks to wait longer than MaxDelay.
+func (t *RetryTransport) delay(err error, attempt int) (time.Duration, bool) {
+	base, max := t.BaseDelay, t.MaxDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	if max <= 0 {
+		max = DefaultRetryMaxDelay
+	}
+
+	for _, detail := range Details(err) {
+		if info, ok := detail.(RetryInfo); ok && info.RetryDelay > 0 {
+			delay := time.Duration(info.RetryDelay)
+			return delay, delay <= max
+		}
+	}
+
+	backoff := base << (attempt - 1)
+	if backoff <= 0 || backoff > max {
+		backoff = max
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1)), true
+}
+
+func (t *RetryTransport) maxAttempts() int {
+	if t.MaxAttempts > 0 {
+		return t.MaxAttempts
+	}
+	return DefaultRetryAttempts
+}
+
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+
+	next := req.Clone(req.Context())
+	next.Body = body
+	return next, true
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, DefaultMaxBodySize))
+	_ = resp.Body.Close()
+}
+
+// retried records the attempt count on errors of requests that were retried.
+func retried(err error, attempts int) error {
+	if attempts < 2 {
+		return err
+	}
+	return Annotate(err, Code(err), retryAttempts(attempts))
+}
+
+func retryAttempts(n int) DebugInfo {
+	return DebugInfo{Detail: fmt.Sprintf("retry: %d attempts", n)}
+}
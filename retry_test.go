@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransport(t *testing.T) {
+	const (
+		rawUnavailable = `{"error":{"code":503,"message":"busy","status":"UNAVAILABLE","details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"0.01s"}]}}`
+		rawNotFound    = `{"error":{"code":404,"message":"missing","status":"NOT_FOUND"}}`
+	)
+
+	var (
+		calls  int32
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, rawNotFound)
+		case r.URL.Path == "/flaky" && n < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, rawUnavailable)
+		case r.URL.Path == "/html" && n < 2:
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, "<html><title>503 Service Temporarily Unavailable</title></html>")
+		case r.URL.Path == "/not-envelope" && n < 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":"backend down"}`)
+		case r.URL.Path == "/truncated" && n < 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, rawUnavailable)
+		case r.URL.Path == "/maintenance":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, "<html><title>Down for maintenance</title></html>")
+		case r.URL.Path == "/down":
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":{"code":503,"message":"down","status":"UNAVAILABLE"}}`)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	reset := func() {
+		atomic.StoreInt32(&calls, 0)
+		bodies = nil
+	}
+
+	client := http.Client{
+		Transport: &RetryTransport{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+		},
+	}
+
+	t.Run("recover", func(t *testing.T) {
+		reset()
+		resp, err := client.Post(srv.URL+"/flaky", "text/plain", strings.NewReader("payload"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_ = resp.Body.Close()
+
+		assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+		assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		reset()
+		client := http.Client{
+			Transport: &RetryTransport{
+				MaxAttempts: 2,
+				Parent:      &RoundTripper{},
+			},
+		}
+
+		_, err := client.Get(srv.URL + "/flaky")
+		assert.Equal(t, Unavailable, Code(err))
+		assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+		assert.Contains(t, Details(err), retryAttempts(2))
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		reset()
+		_, err := client.Get(srv.URL + "/missing")
+		assert.Equal(t, NotFound, Code(err))
+		assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+		assert.Empty(t, Details(err))
+	})
+
+	t.Run("no body replay", func(t *testing.T) {
+		reset()
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/flaky", io.NopCloser(strings.NewReader("payload")))
+		_, err := client.Do(req)
+		assert.Equal(t, Unavailable, Code(err))
+		assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("html", func(t *testing.T) {
+		reset()
+		resp, err := client.Get(srv.URL + "/html")
+		if !assert.NoError(t, err) {
+			return
+		}
+		_ = resp.Body.Close()
+		assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("json fallback", func(t *testing.T) {
+		client := http.Client{
+			Transport: &RetryTransport{
+				BaseDelay: time.Millisecond,
+				Parent:    &RoundTripper{MaxBodySize: 32},
+			},
+		}
+
+		for _, path := range []string{"/not-envelope", "/truncated"} {
+			reset()
+			resp, err := client.Get(srv.URL + path)
+			if assert.NoError(t, err, path) {
+				_ = resp.Body.Close()
+			}
+			assert.EqualValues(t, 2, atomic.LoadInt32(&calls), path)
+		}
+	})
+
+	t.Run("retry after too long", func(t *testing.T) {
+		reset()
+		start := time.Now()
+		_, err := client.Get(srv.URL + "/maintenance")
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, Unavailable, Code(err))
+		assert.Contains(t, Details(err), RetryInfo{RetryDelay: Duration(time.Hour)})
+		assert.NotContains(t, Details(err), retryAttempts(1))
+		assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		reset()
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/down", nil)
+		_, err := client.Do(req)
+		assert.Equal(t, Unavailable, Code(err))
+		assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	rt := &RetryTransport{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for attempt, max := range map[int]time.Duration{1: 10, 2: 20, 3: 40, 4: 50, 80: 50} {
+		max *= time.Millisecond
+		delay, ok := rt.delay(Unavailable, attempt)
+		assert.True(t, ok)
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.LessOrEqual(t, delay, max)
+	}
+
+	err := Annotate(Unavailable, RetryInfo{RetryDelay: Duration(40 * time.Millisecond)})
+	delay, ok := rt.delay(err, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 40*time.Millisecond, delay)
+
+	err = Annotate(Unavailable, RetryInfo{RetryDelay: Duration(time.Second)})
+	_, ok = rt.delay(err, 1)
+	assert.False(t, ok)
+}
@@ -3,16 +3,105 @@ package errors
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
+	"mime"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+const (
+	DefaultMaxBodySize      = 64 << 10
+	DefaultTruncationMarker = "...(truncated)"
+
+	maxSummaryLength = 128
+)
+
+var htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title`)
+
+var DefaultResponseHeaders = []string{
+	"Content-Type",
+	"Date",
+	"Retry-After",
+	"Server",
+	"X-Request-Id",
+}
+
+// ResponseMetadata describes the failed response. Response is only set with
+// RoundTripper.PassThrough; its body is still readable and must be closed by
+// the caller.
+type ResponseMetadata struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Latency    time.Duration
+	Response   *http.Response
+}
+
+func ResponseInfo(err error) (info ResponseMetadata, ok bool) {
+	var rErr *responseError
+	if ok = errors.As(err, &rErr); ok {
+		info = rErr.info
+	}
+	return
+}
+
+type responseError struct {
+	cause error
+	info  ResponseMetadata
+}
+
+func (e *responseError) Unwrap() error { return e.cause }
+func (e *responseError) Error() string { return e.cause.Error() }
+
+func (e *responseError) Format(f fmt.State, verb rune) {
+	_, _ = fmt.Fprintf(f, fmt.FormatString(f, verb), e.cause)
+}
+
 type RoundTripper struct {
-	Parent http.RoundTripper
+	Parent           http.RoundTripper
+	MaxBodySize      int64
+	TruncationMarker string
+	Headers          []string
+	Success          func(resp *http.Response) bool
+	Overrides        map[int]StatusCode
+	PassThrough      bool
+	Service          string
+	Route            func(req *http.Request) string
+	Metrics          MetricSink
+	Tracer           Tracer
 }
 
 func (e *RoundTripper) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	if e.Metrics != nil {
+		defer func() {
+			var route string
+			if e.Route != nil {
+				route = e.Route(req)
+			}
+			e.Metrics.Add(NewMetric(MetricClient, route, err), 1)
+		}()
+	}
+	if e.Tracer != nil {
+		defer func() {
+			var status int
+			if resp != nil {
+				status = resp.StatusCode
+			} else if info, ok := ResponseInfo(err); ok {
+				status = info.StatusCode
+			}
+			e.Tracer.TraceError(req.Context(), newTrace(err, status, nil))
+		}()
+	}
+
+	start := time.Now()
 	if resp, err = e.next(req); err != nil {
 		return
 	}
@@ -21,9 +110,27 @@ func (e *RoundTripper) RoundTrip(req *http.Request) (resp *http.Response, err er
 		return
 	}
 
-	err = e.onError(resp, err)
-	resp = nil
-	return
+	err = e.onError(resp)
+	err = e.annotateHeaders(resp, err)
+	if code, ok := e.Overrides[resp.StatusCode]; ok {
+		err = Annotate(err, code)
+	}
+	if e.Service != "" {
+		err = e.annotateUpstream(req, err)
+	}
+
+	info := ResponseMetadata{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     e.selectHeaders(resp.Header),
+		Latency:    time.Since(start),
+	}
+
+	if e.PassThrough {
+		info.Response = resp
+	}
+	return nil, &responseError{cause: err, info: info}
 }
 
 func (e *RoundTripper) next(req *http.Request) (resp *http.Response, err error) {
@@ -34,24 +141,187 @@ func (e *RoundTripper) next(req *http.Request) (resp *http.Response, err error)
 	return rt.RoundTrip(req)
 }
 
-func (e *RoundTripper) onError(resp *http.Response, err error) error {
-	defer func() { _ = resp.Body.Close() }()
-
-	var buf bytes.Buffer
-	if _, err = buf.ReadFrom(resp.Body); err != nil {
+func (e *RoundTripper) onError(resp *http.Response) error {
+	body, truncated, err := e.readBody(resp)
+	if err != nil {
 		return err
 	}
 
+	code := e.statusCode(resp.StatusCode)
 	if e.isJson(resp) {
-		dec := NewDecoder(json.NewDecoder(&buf))
-		return dec.Decode()
+		err = NewDecoder(json.NewDecoder(bytes.NewReader(body))).Decode()
+		_, decoded := Unwrap(err).(StatusCode)
+		switch {
+		case decoded && Code(err) != Unknown:
+			return err
+		case decoded:
+			// An envelope without a status.
+			return Annotate(err, code)
+		default:
+			// Not an envelope, or cut off at MaxBodySize.
+			return Annotate(err, e.excerpt(code, body, truncated)...)
+		}
+	}
+
+	return Annotate(New(e.summarize(resp, body)), e.excerpt(code, body, truncated)...)
+}
+
+func (e *RoundTripper) excerpt(code StatusCode, body []byte, truncated bool) []Annotation {
+	annotations := []Annotation{code}
+	if len(body) > 0 {
+		excerpt := string(body)
+		if truncated {
+			excerpt += e.truncationMarker()
+		}
+		annotations = append(annotations, DebugInfo{Detail: excerpt})
+	}
+	return annotations
+}
+
+func (e *RoundTripper) readBody(resp *http.Response) (body []byte, truncated bool, err error) {
+	limit := e.maxBodySize()
+	body, err = io.ReadAll(io.LimitReader(resp.Body, limit+1))
+
+	if e.PassThrough {
+		replay := bytes.NewReader(append([]byte(nil), body...))
+		resp.Body = readCloser{io.MultiReader(replay, resp.Body), resp.Body}
+	} else {
+		_ = resp.Body.Close()
+	}
+
+	if err != nil {
+		return
+	}
+	if truncated = int64(len(body)) > limit; truncated {
+		body = body[:limit]
+	}
+	return
+}
+
+func (e *RoundTripper) annotateHeaders(resp *http.Response, err error) error {
+	var (
+		present     = make(map[string]bool)
+		annotations []Annotation
+	)
+	for _, detail := range Details(err) {
+		present[detail.TypeUrl()] = true
+	}
+
+	if id := resp.Header.Get("X-Request-Id"); id != "" && !present[TypeUrlRequestInfo] {
+		annotations = append(annotations, RequestInfo{RequestId: id})
 	}
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok && !present[TypeUrlRetryInfo] {
+		annotations = append(annotations, RetryInfo{RetryDelay: Duration(delay)})
+	}
+	if len(annotations) == 0 {
+		return err
+	}
+	return Annotate(err, append([]Annotation{Code(err)}, annotations...)...)
+}
+
+// annotateUpstream names the upstream in the message. An ErrorInfo is only
+// added when the upstream sent none, so its reason and domain are kept.
+func (e *RoundTripper) annotateUpstream(req *http.Request, err error) error {
+	route := req.URL.Path
+	if e.Route != nil {
+		route = e.Route(req)
+	}
+
+	code := Code(err)
+	annotations := []Annotation{
+		code,
+		Message(fmt.Sprintf("%s %s %s: %s", e.Service, req.Method, route, code)),
+	}
+	if !hasErrorInfo(err) {
+		annotations = append(annotations, ErrorInfo{
+			Reason: code.String(),
+			Domain: req.URL.Hostname(),
+			Metadata: map[string]string{
+				"service": e.Service,
+				"method":  req.Method,
+				"route":   route,
+			},
+		})
+	}
+	return Annotate(err, annotations...)
+}
+
+func hasErrorInfo(err error) bool {
+	for _, detail := range Details(err) {
+		if _, ok := detail.(ErrorInfo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RoundTripper) selectHeaders(header http.Header) http.Header {
+	names := e.Headers
+	if names == nil {
+		names = DefaultResponseHeaders
+	}
+
+	out := make(http.Header, len(names))
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			out[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return out
+}
+
+func (e *RoundTripper) summarize(resp *http.Response, body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return http.StatusText(resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		if title := htmlTitle(body); title != "" {
+			return e.clip(title)
+		}
+	case "text/plain", "":
+		line, _, _ := strings.Cut(strings.TrimSpace(string(body)), "\n")
+		return e.clip(strings.TrimSpace(line))
+	}
+	return fmt.Sprintf("unexpected %s response", mediaType)
+}
 
-	msg := strings.TrimSpace(buf.String())
-	return fmt.Errorf("%s: %w", msg, Unknown)
+func (e *RoundTripper) clip(s string) string {
+	if len(s) <= maxSummaryLength {
+		return s
+	}
+	cut := maxSummaryLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + e.truncationMarker()
+}
+
+// statusCode derives a code from the HTTP status, preferring Overrides.
+func (e *RoundTripper) statusCode(status int) StatusCode {
+	return HttpMapping{Statuses: e.Overrides}.StatusCode(status)
+}
+
+func (e *RoundTripper) maxBodySize() int64 {
+	if e.MaxBodySize > 0 {
+		return e.MaxBodySize
+	}
+	return DefaultMaxBodySize
+}
+
+func (e *RoundTripper) truncationMarker() string {
+	if e.TruncationMarker != "" {
+		return e.TruncationMarker
+	}
+	return DefaultTruncationMarker
 }
 
 func (e *RoundTripper) isSuccess(r *http.Response) bool {
+	if e.Success != nil {
+		return e.Success(r)
+	}
 	return r.StatusCode > 199 && r.StatusCode < 300
 }
 
@@ -59,3 +329,33 @@ func (e *RoundTripper) isJson(resp *http.Response) bool {
 	contentType := resp.Header.Get("Content-Type")
 	return strings.HasPrefix(contentType, "application/json")
 }
+
+func htmlTitle(body []byte) string {
+	m := htmlTitlePattern.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	title := html.UnescapeString(string(m[1]))
+	return strings.Join(strings.Fields(title), " ")
+}
+
+func parseRetryAfter(value string) (delay time.Duration, ok bool) {
+	if value == "" {
+		return
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, seconds >= 0
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay = time.Until(at); delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
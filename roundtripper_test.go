@@ -1,22 +1,30 @@
 package errors
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestRoundTripper(t *testing.T) {
-	const response = `{"data": "response data"}`
+	const (
+		response = `{"data": "response data"}`
+		htmlPage = "<html><head><TITLE>502 Bad\n  Gateway &amp; Co</TITLE></head><body>" +
+			"<p>The upstream server is not responding.</p></body></html>"
+	)
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -32,6 +40,35 @@ func TestRoundTripper(t *testing.T) {
 			_, _ = io.WriteString(w, "123")
 		case "plaintext":
 			http.Error(w, sql.ErrNoRows.Error(), http.StatusNotFound)
+		case "html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = io.WriteString(w, htmlPage)
+		case "binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write(bytes.Repeat([]byte{0xff}, DefaultMaxBodySize*2))
+		case "throttled":
+			w.Header().Set("X-Request-Id", "req-1")
+			w.Header().Set("Retry-After", "3")
+			w.Header().Set("X-Internal", "secret")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error":{"code":429,"message":"slow down","status":"RESOURCE_EXHAUSTED"}}`)
+		case "quota":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error":{"code":429,"message":"quota exceeded","status":"RESOURCE_EXHAUSTED",`+
+				`"details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"RATE_LIMIT_EXCEEDED","domain":"billing.example.com"}]}}`)
+		case "not-envelope":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":"backend down"}`)
+		case "no-status":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":{"code":503,"message":"down"}}`)
+		case "cached":
+			w.WriteHeader(http.StatusNotModified)
+		case "empty":
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusServiceUnavailable)
 		}
 	}))
 	defer srv.Close()
@@ -84,8 +121,29 @@ func TestRoundTripper(t *testing.T) {
 		}
 
 		uErr := &url.Error{Op: "Get", URL: srv.URL + "/internal", Err: jErr}
-		assert.Equal(t, Unknown, Code(err))
+		assert.Equal(t, Internal, Code(err))
 		assert.Equal(t, uErr.Error(), err.Error())
+		assert.Equal(t, []Any{DebugInfo{Detail: "123"}}, Details(err))
+	})
+
+	t.Run("json fallback", func(t *testing.T) {
+		_, err := client.Get(srv.URL + "/not-envelope")
+		assert.Equal(t, Unavailable, Code(err))
+		assert.Equal(t, []Any{DebugInfo{Detail: `{"error":"backend down"}`}}, Details(err))
+
+		_, err = client.Get(srv.URL + "/no-status")
+		assert.Equal(t, Unavailable, Code(err))
+		assert.True(t, strings.HasSuffix(err.Error(), ": down"), err.Error())
+		assert.Empty(t, Details(err))
+
+		limited := http.Client{Transport: &RoundTripper{MaxBodySize: 32}}
+		_, err = limited.Get(srv.URL + "/sad")
+		assert.Equal(t, Internal, Code(err))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		details := Details(err)
+		if assert.Len(t, details, 1) {
+			assert.Equal(t, rawFullError[:32]+DefaultTruncationMarker, details[0].(DebugInfo).Detail)
+		}
 	})
 
 	t.Run("plaintext", func(t *testing.T) {
@@ -97,10 +155,239 @@ func TestRoundTripper(t *testing.T) {
 		cause := &url.Error{
 			Op:  "Get",
 			URL: srv.URL + "/plaintext",
-			Err: fmt.Errorf("%s: %w", sql.ErrNoRows, Unknown),
+			Err: sql.ErrNoRows,
 		}
-		assert.ErrorIs(t, err, Unknown)
-		assert.Equal(t, Unknown, Code(err))
+		assert.Equal(t, NotFound, Code(err))
 		assert.Equal(t, cause.Error(), err.Error())
+		assert.Equal(t, []Any{DebugInfo{Detail: sql.ErrNoRows.Error() + "\n"}}, Details(err))
+	})
+
+	t.Run("html", func(t *testing.T) {
+		_, err := client.Get(srv.URL + "/html")
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, Internal, Code(err))
+		assert.True(t, strings.HasSuffix(err.Error(), ": 502 Bad Gateway & Co"), err.Error())
+		assert.NotContains(t, err.Error(), "upstream server")
+		assert.Equal(t, []Any{DebugInfo{Detail: htmlPage}}, Details(err))
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		client := http.Client{
+			Transport: &RoundTripper{
+				MaxBodySize:      16,
+				TruncationMarker: "<cut>",
+			},
+		}
+
+		_, err := client.Get(srv.URL + "/binary")
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, Internal, Code(err))
+		assert.True(t, strings.HasSuffix(err.Error(), ": unexpected application/octet-stream response"), err.Error())
+
+		details := Details(err)
+		if assert.Len(t, details, 1) {
+			excerpt := details[0].(DebugInfo).Detail
+			assert.Equal(t, strings.Repeat("\xff", 16)+"<cut>", excerpt)
+		}
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		_, err := client.Post(srv.URL+"/throttled", "text/plain", nil)
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, ResourceExhausted, Code(err))
+		assert.Equal(t, []Any{
+			RequestInfo{RequestId: "req-1"},
+			RetryInfo{RetryDelay: Duration(3 * time.Second)},
+		}, Details(err))
+
+		info, ok := ResponseInfo(err)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.MethodPost, info.Method)
+			assert.Equal(t, srv.URL+"/throttled", info.URL)
+			assert.Equal(t, http.StatusTooManyRequests, info.StatusCode)
+			assert.Equal(t, "3", info.Header.Get("Retry-After"))
+			assert.Empty(t, info.Header.Get("X-Internal"))
+			assert.Positive(t, info.Latency)
+		}
+
+		_, ok = ResponseInfo(Internal)
+		assert.False(t, ok)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		client := http.Client{
+			Transport: &RoundTripper{
+				Success: func(resp *http.Response) bool {
+					return resp.StatusCode < 300 || resp.StatusCode == http.StatusNotModified
+				},
+			},
+		}
+
+		resp, err := client.Get(srv.URL + "/cached")
+		if assert.NoError(t, err) {
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		}
+
+		_, err = client.Get(srv.URL + "/sad")
+		assert.Equal(t, Internal, Code(err))
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		client := http.Client{
+			Transport: &RoundTripper{
+				Overrides: map[int]StatusCode{
+					http.StatusNotFound:        NotFound,
+					http.StatusTooManyRequests: Unavailable,
+				},
+			},
+		}
+
+		_, err := client.Get(srv.URL + "/plaintext")
+		assert.Equal(t, NotFound, Code(err))
+
+		_, err = client.Get(srv.URL + "/throttled")
+		assert.Equal(t, Unavailable, Code(err))
+		assert.Len(t, Details(err), 2)
+
+		client.Transport = &RoundTripper{
+			Overrides: map[int]StatusCode{http.StatusBadGateway: Unavailable},
+		}
+		_, err = client.Get(srv.URL + "/html")
+		assert.Equal(t, Unavailable, Code(err))
+
+		_, err = client.Get(srv.URL + "/plaintext")
+		assert.Equal(t, NotFound, Code(err))
+	})
+
+	t.Run("pass through", func(t *testing.T) {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		client := http.Client{Transport: &RoundTripper{PassThrough: true}}
+		resp, err := client.Get(srv.URL + "/sad")
+		assert.Nil(t, resp)
+		assert.Equal(t, fullError.code, Code(err))
+		assert.Empty(t, logs.String())
+
+		info, ok := ResponseInfo(err)
+		if assert.True(t, ok) && assert.NotNil(t, info.Response) {
+			data, _ := io.ReadAll(info.Response.Body)
+			_ = info.Response.Body.Close()
+			assert.Equal(t, rawFullError, string(data))
+		}
+
+		rt := &RoundTripper{PassThrough: true}
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/throttled", nil)
+		resp, err = rt.RoundTrip(req)
+		assert.Nil(t, resp)
+		assert.Equal(t, ResourceExhausted, Code(err))
+
+		info, ok = ResponseInfo(err)
+		if assert.True(t, ok) && assert.NotNil(t, info.Response) {
+			data, _ := io.ReadAll(info.Response.Body)
+			_ = info.Response.Body.Close()
+			assert.Contains(t, string(data), "slow down")
+		}
+	})
+
+	t.Run("upstream", func(t *testing.T) {
+		client := http.Client{
+			Transport: &RoundTripper{
+				Service: "billing-api",
+				Route:   func(*http.Request) string { return "/v1/invoices" },
+			},
+		}
+
+		_, err := client.Get(srv.URL + "/throttled")
+		if !assert.Error(t, err) {
+			return
+		}
+
+		var a *annotated
+		if assert.ErrorAs(t, err, &a) {
+			assert.Equal(t, "billing-api GET /v1/invoices: 429 RESOURCE_EXHAUSTED: slow down", a.Error())
+			assert.Contains(t, fmt.Sprintf("%+v", a), `status: "429 RESOURCE_EXHAUSTED"`)
+			assert.Contains(t, fmt.Sprintf("%+v", a), `message: "billing-api GET /v1/invoices: 429 RESOURCE_EXHAUSTED: slow down"`)
+		}
+		assert.Equal(t, ResourceExhausted, Code(err))
+		assert.Contains(t, Details(err), ErrorInfo{
+			Reason: "RESOURCE_EXHAUSTED",
+			Domain: "127.0.0.1",
+			Metadata: map[string]string{
+				"service": "billing-api",
+				"method":  http.MethodGet,
+				"route":   "/v1/invoices",
+			},
+		})
+
+		_, err = client.Get(srv.URL + "/quota")
+		assert.Equal(t, []Any{ErrorInfo{Reason: "RATE_LIMIT_EXCEEDED", Domain: "billing.example.com"}}, Details(err))
+		assert.True(t, strings.HasPrefix(err.Error(), fmt.Sprintf("Get %q: billing-api GET /v1/invoices: ", srv.URL+"/quota")), err.Error())
+
+		m := NewMetric(MetricClient, "/v1/invoices", err)
+		assert.Equal(t, "RATE_LIMIT_EXCEEDED", m.Reason)
+		assert.Equal(t, "billing.example.com", m.Domain)
 	})
+
+	t.Run("metrics", func(t *testing.T) {
+		var metrics []Metric
+		client := http.Client{
+			Transport: &RoundTripper{
+				Route: func(*http.Request) string { return "/v1/data" },
+				Metrics: MetricSinkFunc(func(m Metric, delta int64) {
+					metrics = append(metrics, m)
+				}),
+			},
+		}
+
+		resp, err := client.Get(srv.URL + "/happy")
+		if assert.NoError(t, err) {
+			_ = resp.Body.Close()
+		}
+		_, _ = client.Get(srv.URL + "/throttled")
+
+		assert.Equal(t, []Metric{
+			{Side: MetricClient, Route: "/v1/data"},
+			{Side: MetricClient, Route: "/v1/data", Status: ResourceExhausted},
+		}, metrics)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := client.Get(srv.URL + "/empty")
+		if !assert.Error(t, err) {
+			return
+		}
+
+		assert.Equal(t, Unavailable, Code(err))
+		assert.True(t, strings.HasSuffix(err.Error(), ": Service Unavailable"), err.Error())
+		assert.Empty(t, Details(err))
+	})
+
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, ok := parseRetryAfter("120")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour, delay, float64(2*time.Second))
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("-1")
+	assert.False(t, ok)
 }
@@ -0,0 +1,217 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (c StatusCode) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("status", c.String()),
+		slog.Int("http", c.Http()),
+	)
+}
+
+func (e annotated) LogValue() slog.Value  { return logValue(&e) }
+func (e propagated) LogValue() slog.Value { return logValue(&e) }
+
+func logValue(err error, mappers ...DetailMapper) slog.Value {
+	a := Flatten(err, mappers...).(*annotated)
+
+	attrs := []slog.Attr{
+		slog.String("status", a.code.String()),
+		slog.Int("http", a.code.Http()),
+		slog.String("message", a.message),
+	}
+	if len(a.details) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "details", Value: detailsValue(a.details)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func detailsValue(details []Any) slog.Value {
+	var (
+		seen  = make(map[string]int, len(details))
+		attrs = make([]slog.Attr, 0, len(details))
+	)
+	for _, detail := range details {
+		typeUrl := detail.TypeUrl()
+		key := typeUrl[strings.LastIndexAny(typeUrl, "./")+1:]
+
+		if seen[key]++; seen[key] > 1 {
+			key += "_" + strconv.Itoa(seen[key])
+		}
+		attrs = append(attrs, slog.Any(key, detail))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func stringsValue(pairs [][2]string) slog.Value {
+	attrs := make([]slog.Attr, len(pairs))
+	for i, pair := range pairs {
+		attrs[i] = slog.String(pair[0], pair[1])
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (d AnyDetail) LogValue() slog.Value {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, len(keys))
+	for i, key := range keys {
+		attrs[i] = slog.Any(key, d[key])
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (d RawDetail) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("type", d.TypeUrl()),
+		slog.String("raw", string(d.Raw)),
+	}
+	if d.Err != nil {
+		attrs = append(attrs, slog.String("err", d.Err.Error()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (d RetryInfo) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Duration("retry_delay", time.Duration(d.RetryDelay)),
+	)
+}
+
+func (d DebugInfo) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("detail", d.Detail),
+		slog.Any("stack", d.StackEntries),
+	)
+}
+
+func (d ResourceInfo) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("resource_type", d.ResourceType),
+		slog.String("resource_name", d.ResourceName),
+		slog.String("owner", d.Owner),
+		slog.String("description", d.Description),
+	)
+}
+
+func (d BadRequest) LogValue() slog.Value {
+	pairs := make([][2]string, len(d.FieldViolations))
+	for i, v := range d.FieldViolations {
+		pairs[i] = [2]string{v.Field, v.Description}
+	}
+	return slog.GroupValue(slog.Attr{Key: "field_violations", Value: stringsValue(pairs)})
+}
+
+func (d PreconditionFailure) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("violations", d.Violations))
+}
+
+func (d ErrorInfo) LogValue() slog.Value {
+	keys := make([]string, 0, len(d.Metadata))
+	for key := range d.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([][2]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = [2]string{key, d.Metadata[key]}
+	}
+	return slog.GroupValue(
+		slog.String("reason", d.Reason),
+		slog.String("domain", d.Domain),
+		slog.Attr{Key: "metadata", Value: stringsValue(pairs)},
+	)
+}
+
+func (d QuotaFailure) LogValue() slog.Value {
+	pairs := make([][2]string, len(d.Violations))
+	for i, v := range d.Violations {
+		pairs[i] = [2]string{v.Subject, v.Description}
+	}
+	return slog.GroupValue(slog.Attr{Key: "violations", Value: stringsValue(pairs)})
+}
+
+func (d RequestInfo) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("request_id", d.RequestId),
+		slog.String("serving_data", d.ServingData),
+	)
+}
+
+func (d Help) LogValue() slog.Value {
+	pairs := make([][2]string, len(d.Links))
+	for i, v := range d.Links {
+		pairs[i] = [2]string{v.Description, v.Url}
+	}
+	return slog.GroupValue(slog.Attr{Key: "links", Value: stringsValue(pairs)})
+}
+
+func (d LocalizedMessage) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("local", d.Local),
+		slog.String("message", d.Message),
+	)
+}
+
+type LogHandler struct {
+	Handler slog.Handler
+	Mappers []DetailMapper
+}
+
+func NewLogHandler(h slog.Handler, mappers ...DetailMapper) *LogHandler {
+	return &LogHandler{Handler: h, Mappers: mappers}
+}
+
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.expand(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		expanded[i] = h.expand(a)
+	}
+	return &LogHandler{Handler: h.Handler.WithAttrs(expanded), Mappers: h.Mappers}
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name), Mappers: h.Mappers}
+}
+
+func (h *LogHandler) expand(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := a.Value.Any().(error); ok && err != nil {
+			return slog.Attr{Key: a.Key, Value: logValue(err, h.Mappers...)}
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = h.expand(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	}
+	return a
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogValue(t *testing.T) {
+	t.Run("status", func(t *testing.T) {
+		v := NotFound.LogValue()
+		assert.Equal(t, "[status=NOT_FOUND http=404]", v.String())
+	})
+
+	t.Run("annotated", func(t *testing.T) {
+		err := Annotate(sql.ErrNoRows, NotFound, Message(msg), resourceInfo, localizedMessage, localizedMessage)
+		attrs := err.(slog.LogValuer).LogValue().Group()
+
+		if assert.Len(t, attrs, 4) {
+			assert.Equal(t, "NOT_FOUND", attrs[0].Value.String())
+			assert.Equal(t, int64(404), attrs[1].Value.Int64())
+			assert.Equal(t, err.Error(), attrs[2].Value.String())
+
+			var keys []string
+			for _, a := range attrs[3].Value.Group() {
+				keys = append(keys, a.Key)
+			}
+			assert.Equal(t, []string{"ResourceInfo", "LocalizedMessage", "LocalizedMessage_2"}, keys)
+		}
+	})
+
+	t.Run("details", func(t *testing.T) {
+		for _, detail := range details {
+			valuer, ok := detail.Any.(slog.LogValuer)
+			if assert.True(t, ok, detail.Type) {
+				assert.Equal(t, slog.KindGroup, valuer.LogValue().Kind(), detail.Type)
+				assert.NotEmpty(t, valuer.LogValue().Group(), detail.Type)
+			}
+		}
+
+		raw := RawDetail{Type: typeUrlCustom, Raw: json.RawMessage(`{"@type":"custom/type"}`)}
+		assert.Equal(t, "[type=custom/type raw={\"@type\":\"custom/type\"}]", raw.LogValue().String())
+	})
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil), HideDebugInfo))
+
+	err := Annotate(sql.ErrNoRows, NotFound, resourceInfo, debugInfo)
+	logger.With("upstream", Unavailable).
+		WithGroup("req").
+		Error("failed", "err", err, slog.Group("job", "cause", sql.ErrConnDone), "id", 1)
+
+	var out struct {
+		Upstream map[string]interface{} `json:"upstream"`
+		Req      struct {
+			Err struct {
+				Status  string                            `json:"status"`
+				Http    int                               `json:"http"`
+				Message string                            `json:"message"`
+				Details map[string]map[string]interface{} `json:"details"`
+			} `json:"err"`
+			Job struct {
+				Cause map[string]interface{} `json:"cause"`
+			} `json:"job"`
+			Id int `json:"id"`
+		} `json:"req"`
+	}
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), &out)) {
+		return
+	}
+
+	assert.Equal(t, "UNAVAILABLE", out.Upstream["status"])
+	assert.Equal(t, "NOT_FOUND", out.Req.Err.Status)
+	assert.Equal(t, 404, out.Req.Err.Http)
+	assert.Equal(t, sql.ErrNoRows.Error(), out.Req.Err.Message)
+	assert.Contains(t, out.Req.Err.Details, "ResourceInfo")
+	assert.NotContains(t, out.Req.Err.Details, "DebugInfo")
+	assert.Equal(t, "UNKNOWN", out.Req.Job.Cause["status"])
+	assert.Equal(t, sql.ErrConnDone.Error(), out.Req.Job.Cause["message"])
+	assert.Equal(t, 1, out.Req.Id)
+}
@@ -68,6 +68,20 @@ var (
 		http.StatusInternalServerError,
 		http.StatusUnauthorized,
 	}
+	httpStatuses = map[int]StatusCode{
+		http.StatusOK:                  OK,
+		http.StatusBadRequest:          InvalidArgument,
+		http.StatusUnauthorized:        Unauthenticated,
+		http.StatusForbidden:           PermissionDenied,
+		http.StatusNotFound:            NotFound,
+		http.StatusConflict:            Aborted,
+		http.StatusTooManyRequests:     ResourceExhausted,
+		499:                            Cancelled,
+		http.StatusInternalServerError: Internal,
+		http.StatusNotImplemented:      Unimplemented,
+		http.StatusServiceUnavailable:  Unavailable,
+		http.StatusGatewayTimeout:      DeadlineExceeded,
+	}
 )
 
 type StatusCode int
@@ -104,6 +118,42 @@ func (c StatusCode) Http() int {
 	return http.StatusInternalServerError
 }
 
+func FromHttp(status int) StatusCode {
+	if code, ok := httpStatuses[status]; ok {
+		return code
+	}
+
+	switch {
+	case status >= 200 && status < 300:
+		return OK
+	case status >= 400 && status < 500:
+		return FailedPrecondition
+	case status >= 500 && status < 600:
+		return Internal
+	default:
+		return Unknown
+	}
+}
+
+type HttpMapping struct {
+	Codes    map[StatusCode]int
+	Statuses map[int]StatusCode
+}
+
+func (m HttpMapping) Http(code StatusCode) int {
+	if status, ok := m.Codes[code]; ok {
+		return status
+	}
+	return code.Http()
+}
+
+func (m HttpMapping) StatusCode(status int) StatusCode {
+	if code, ok := m.Statuses[status]; ok {
+		return code
+	}
+	return FromHttp(status)
+}
+
 type StatusName string
 
 func (s StatusName) StatusCode() StatusCode {
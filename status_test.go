@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -62,6 +63,40 @@ func TestStatusTemporary(t *testing.T) {
 	}
 }
 
+func TestFromHttp(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		for status, code := range httpStatuses {
+			assert.Equal(t, code, FromHttp(status))
+			assert.Equal(t, status, code.Http())
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		assert.Equal(t, OK, FromHttp(http.StatusNoContent))
+		assert.Equal(t, FailedPrecondition, FromHttp(http.StatusPreconditionFailed))
+		assert.Equal(t, Internal, FromHttp(http.StatusBadGateway))
+		assert.Equal(t, Unknown, FromHttp(http.StatusNotModified))
+	})
+}
+
+func TestHttpMapping(t *testing.T) {
+	var m HttpMapping
+	for i := 0; i < int(totalStatus); i++ {
+		s := StatusCode(i)
+		assert.Equal(t, s.Http(), m.Http(s))
+	}
+	assert.Equal(t, Aborted, m.StatusCode(http.StatusConflict))
+
+	m = HttpMapping{
+		Codes:    map[StatusCode]int{FailedPrecondition: http.StatusPreconditionFailed},
+		Statuses: map[int]StatusCode{http.StatusConflict: AlreadyExists},
+	}
+	assert.Equal(t, http.StatusPreconditionFailed, m.Http(FailedPrecondition))
+	assert.Equal(t, http.StatusBadRequest, m.Http(InvalidArgument))
+	assert.Equal(t, AlreadyExists, m.StatusCode(http.StatusConflict))
+	assert.Equal(t, NotFound, m.StatusCode(http.StatusNotFound))
+}
+
 type MockModifier struct {
 	Code     StatusCode
 	Messages []string
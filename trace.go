@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+const (
+	AttrErrorStatus    = "error.status"
+	AttrErrorReason    = "error.reason"
+	AttrErrorDomain    = "error.domain"
+	AttrHttpStatusCode = "http.status_code"
+)
+
+type SpanStatus int
+
+const (
+	SpanOK SpanStatus = iota
+	SpanError
+)
+
+func (s SpanStatus) String() string {
+	if s == SpanOK {
+		return "ok"
+	}
+	return "error"
+}
+
+type TraceAttribute struct {
+	Key   string
+	Value interface{}
+}
+
+type TraceEvent struct {
+	Name       string
+	Attributes []TraceAttribute
+}
+
+type Trace struct {
+	Status      SpanStatus
+	Description string
+	Attributes  []TraceAttribute
+	Events      []TraceEvent
+}
+
+func NewTrace(err error) Trace {
+	return newTrace(err, 0, nil)
+}
+
+func newTrace(err error, httpStatus int, mappers []DetailMapper) (t Trace) {
+	if httpStatus == 0 {
+		if info, ok := ResponseInfo(err); ok {
+			httpStatus = info.StatusCode
+		}
+	}
+
+	if err == nil {
+		if httpStatus != 0 {
+			t.Attributes = []TraceAttribute{{AttrHttpStatusCode, httpStatus}}
+		}
+		return
+	}
+
+	a := Flatten(err, mappers...).(*annotated)
+	if httpStatus == 0 {
+		httpStatus = a.code.Http()
+	}
+
+	t.Status = SpanError
+	t.Description = a.message
+	t.Attributes = []TraceAttribute{
+		{AttrErrorStatus, a.code.String()},
+		{AttrHttpStatusCode, httpStatus},
+	}
+
+	var infoSeen bool
+	for _, detail := range a.details {
+		if info, ok := detail.(ErrorInfo); ok && !infoSeen {
+			infoSeen = true
+			t.Attributes = append(t.Attributes,
+				TraceAttribute{AttrErrorReason, info.Reason},
+				TraceAttribute{AttrErrorDomain, info.Domain},
+			)
+		}
+		t.Events = append(t.Events, TraceEvent{
+			Name:       detail.TypeUrl(),
+			Attributes: detailAttributes(detail),
+		})
+	}
+	return
+}
+
+func detailAttributes(detail Any) (out []TraceAttribute) {
+	valuer, ok := detail.(slog.LogValuer)
+	if !ok {
+		data, _ := json.Marshal(detail)
+		return []TraceAttribute{{"value", string(data)}}
+	}
+
+	var walk func(prefix string, attrs []slog.Attr)
+	walk = func(prefix string, attrs []slog.Attr) {
+		for _, a := range attrs {
+			v := a.Value.Resolve()
+			if v.Kind() == slog.KindGroup {
+				walk(prefix+a.Key+".", v.Group())
+			} else {
+				out = append(out, TraceAttribute{prefix + a.Key, v.Any()})
+			}
+		}
+	}
+	walk("", valuer.LogValue().Group())
+	return
+}
+
+type Tracer interface {
+	TraceError(ctx context.Context, t Trace)
+}
+
+type TracerFunc func(ctx context.Context, t Trace)
+
+func (fn TracerFunc) TraceError(ctx context.Context, t Trace) { fn(ctx, t) }
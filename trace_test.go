@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrace(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		tr := NewTrace(nil)
+		assert.Equal(t, SpanOK, tr.Status)
+		assert.Equal(t, "ok", tr.Status.String())
+		assert.Empty(t, tr.Attributes)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := Annotate(sql.ErrNoRows, NotFound, resourceInfo, errInfo, any)
+		tr := NewTrace(err)
+
+		assert.Equal(t, SpanError, tr.Status)
+		assert.Equal(t, "error", tr.Status.String())
+		assert.Equal(t, sql.ErrNoRows.Error(), tr.Description)
+		assert.Equal(t, []TraceAttribute{
+			{AttrErrorStatus, "NOT_FOUND"},
+			{AttrHttpStatusCode, http.StatusNotFound},
+			{AttrErrorReason, errInfo.Reason},
+			{AttrErrorDomain, errInfo.Domain},
+		}, tr.Attributes)
+
+		if assert.Len(t, tr.Events, 3) {
+			assert.Equal(t, TraceEvent{
+				Name: TypeUrlResourceInfo,
+				Attributes: []TraceAttribute{
+					{"resource_type", "1"},
+					{"resource_name", "2"},
+					{"owner", "3"},
+					{"description", "4"},
+				},
+			}, tr.Events[0])
+			assert.Contains(t, tr.Events[1].Attributes, TraceAttribute{"metadata.3", "4"})
+			assert.Equal(t, typeUrlCustom, tr.Events[2].Name)
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		tr := NewTrace(Annotate(Internal, customDetail{Name: "1"}))
+		if assert.Len(t, tr.Events, 1) {
+			assert.Equal(t, []TraceAttribute{{"value", `{"@type":"custom/type","name":"1"}`}}, tr.Events[0].Attributes)
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		tr := NewTrace(Annotate(Unavailable, retryInfo))
+		assert.Equal(t, []TraceAttribute{{"retry_delay", 1100 * time.Millisecond}}, tr.Events[0].Attributes)
+	})
+}
+
+func TestTracer(t *testing.T) {
+	type key struct{}
+	var traces []Trace
+	tracer := TracerFunc(func(ctx context.Context, tr Trace) {
+		assert.Equal(t, "value", ctx.Value(key{}))
+		traces = append(traces, tr)
+	})
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	t.Run("encoder", func(t *testing.T) {
+		traces = nil
+		var buf bytes.Buffer
+		enc := NewEncoder(json.NewEncoder(&buf))
+		enc.Tracer = tracer
+		enc.Mappers = []DetailMapper{HideDebugInfo}
+		enc.Mapping.Codes = map[StatusCode]int{FailedPrecondition: http.StatusPreconditionFailed}
+
+		assert.NoError(t, enc.EncodeContext(ctx, Annotate(rootErr, FailedPrecondition, debugInfo)))
+		if assert.Len(t, traces, 1) {
+			assert.Contains(t, traces[0].Attributes, TraceAttribute{AttrHttpStatusCode, http.StatusPreconditionFailed})
+			assert.Empty(t, traces[0].Events)
+		}
+	})
+
+	t.Run("round tripper", func(t *testing.T) {
+		traces = nil
+		srv := httptest.NewServer(&Handler{
+			Handle: func(w http.ResponseWriter, r *http.Request) error {
+				if r.URL.Path == "/missing" {
+					return WithNotFound(sql.ErrNoRows, resourceInfo)
+				}
+				return nil
+			},
+		})
+		defer srv.Close()
+
+		client := http.Client{Transport: &RoundTripper{Tracer: tracer}}
+		for _, path := range []string{"/", "/missing"} {
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+			if resp, err := client.Do(req); err == nil {
+				_ = resp.Body.Close()
+			}
+		}
+
+		if assert.Len(t, traces, 2) {
+			assert.Equal(t, SpanOK, traces[0].Status)
+			assert.Equal(t, []TraceAttribute{{AttrHttpStatusCode, http.StatusOK}}, traces[0].Attributes)
+			assert.Equal(t, SpanError, traces[1].Status)
+			assert.Contains(t, traces[1].Attributes, TraceAttribute{AttrErrorStatus, "NOT_FOUND"})
+			assert.Len(t, traces[1].Events, 1)
+		}
+	})
+}
@@ -0,0 +1,346 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const DefaultValidateTag = "validate"
+
+var ErrValidation = errors.New("validate: invalid argument")
+
+type PathStyle int
+
+const (
+	PathDotted PathStyle = iota
+	PathPointer
+)
+
+type ValidationRule func(field reflect.Value, param string) (description string, err error)
+
+var builtinRules = map[string]ValidationRule{
+	"required": validateRequired,
+	"min":      validateMin,
+	"max":      validateMax,
+	"len":      validateLen,
+	"email":    validateEmail,
+	"url":      validateURL,
+	"oneof":    validateOneOf,
+}
+
+var DefaultValidator = &Validator{}
+
+func Validate(v interface{}) error {
+	return DefaultValidator.Validate(v)
+}
+
+type Validator struct {
+	Tag   string
+	Path  PathStyle
+	Rules map[string]ValidationRule
+}
+
+func (v *Validator) Validate(in interface{}) error {
+	w := walker{Validator: v, visited: make(map[uintptr]bool)}
+	if err := w.walk(reflect.ValueOf(in), nil); err != nil {
+		return err
+	}
+	if len(w.violations) == 0 {
+		return nil
+	}
+	return WithBadRequest(ErrValidation, BadRequest{FieldViolations: w.violations})
+}
+
+func (v *Validator) rule(name string) (ValidationRule, bool) {
+	if rule, ok := v.Rules[name]; ok {
+		return rule, true
+	}
+	rule, ok := builtinRules[name]
+	return rule, ok
+}
+
+type pathElem struct {
+	name  string
+	index bool
+}
+
+type walker struct {
+	*Validator
+	visited    map[uintptr]bool
+	violations []FieldViolation
+}
+
+func (w *walker) walk(v reflect.Value, path []pathElem) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Pointer {
+			ptr := v.Pointer()
+			if w.visited[ptr] {
+				return nil
+			}
+			w.visited[ptr] = true
+			defer delete(w.visited, ptr)
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return w.walkStruct(v, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), append(path, pathElem{strconv.Itoa(i), true})); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return valueString(keys[i]) < valueString(keys[j])
+		})
+		for _, key := range keys {
+			if err := w.walk(v.MapIndex(key), append(path, pathElem{valueString(key), true})); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkStruct(v reflect.Value, path []pathElem) error {
+	t := v.Type()
+	tagName := w.Tag
+	if tagName == "" {
+		tagName = DefaultValidateTag
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !(f.Anonymous && indirect(f.Type).Kind() == reflect.Struct) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			name = ""
+		}
+		fieldPath := path
+		if !f.Anonymous || name != "" {
+			if name == "" {
+				name = f.Name
+			}
+			fieldPath = append(path[:len(path):len(path)], pathElem{name: name})
+		}
+
+		field := v.Field(i)
+		if tag := f.Tag.Get(tagName); tag != "" && tag != "-" {
+			skip, err := w.check(field, tag, fieldPath)
+			if err != nil {
+				return err
+			}
+			if skip {
+				continue
+			}
+		}
+		if err := w.walk(field, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) check(field reflect.Value, tag string, path []pathElem) (skip bool, err error) {
+	value := field
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			break
+		}
+		value = value.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch {
+		case name == "":
+			continue
+		case name == "omitempty":
+			if isEmpty(value) {
+				return true, nil
+			}
+			continue
+		case name != "required" && isNil(value):
+			continue
+		}
+
+		fn, ok := w.rule(name)
+		if !ok {
+			return false, Annotate(fmt.Errorf("validate: %s: unknown rule %q", w.format(path), name), Internal)
+		}
+		description, rErr := fn(value, param)
+		if rErr != nil {
+			return false, Annotate(fmt.Errorf("validate: %s: rule %q: %w", w.format(path), name, rErr), Internal)
+		}
+		if description != "" {
+			w.violations = append(w.violations, FieldViolation{Field: w.format(path), Description: description})
+			return false, nil
+		}
+	}
+	return false, nil
+}
+
+func (w *walker) format(path []pathElem) string {
+	var sb strings.Builder
+	for i, elem := range path {
+		switch {
+		case w.Path == PathPointer:
+			sb.WriteByte('/')
+			sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(elem.name))
+		case elem.index:
+			sb.WriteString("[" + elem.name + "]")
+		default:
+			if i > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(elem.name)
+		}
+	}
+	return sb.String()
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// valueString formats scalars without Interface, which panics on values
+// reached through unexported embedded structs.
+func valueString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	if v.CanInterface() {
+		return fmt.Sprint(v.Interface())
+	}
+	return v.Type().String()
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Invalid:
+		return true
+	}
+	return false
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func validateRequired(v reflect.Value, _ string) (string, error) {
+	if isEmpty(v) {
+		return "is required", nil
+	}
+	return "", nil
+}
+
+func validateMin(v reflect.Value, param string) (string, error) {
+	return validateBound(v, param, "at least", func(n, limit float64) bool { return n >= limit })
+}
+
+func validateMax(v reflect.Value, param string) (string, error) {
+	return validateBound(v, param, "at most", func(n, limit float64) bool { return n <= limit })
+}
+
+func validateLen(v reflect.Value, param string) (string, error) {
+	return validateBound(v, param, "exactly", func(n, limit float64) bool { return n == limit })
+}
+
+func validateBound(v reflect.Value, param, relation string, ok func(n, limit float64) bool) (string, error) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		n      float64
+		format = "must be %s %s"
+	)
+	switch v.Kind() {
+	case reflect.String:
+		n, format = float64(utf8.RuneCountInString(v.String())), "must be %s %s characters long"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n, format = float64(v.Len()), "must contain %s %s items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		return "", fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+
+	if !ok(n, limit) {
+		return fmt.Sprintf(format, relation, param), nil
+	}
+	return "", nil
+}
+
+func validateEmail(v reflect.Value, _ string) (string, error) {
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+	if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+		return "must be a valid email address", nil
+	}
+	return "", nil
+}
+
+func validateURL(v reflect.Value, _ string) (string, error) {
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+	if u, err := url.ParseRequestURI(v.String()); err != nil || u.Scheme == "" || u.Host == "" {
+		return "must be a valid URL", nil
+	}
+	return "", nil
+}
+
+func validateOneOf(v reflect.Value, param string) (string, error) {
+	options := strings.Fields(param)
+	actual := valueString(v)
+	for _, option := range options {
+		if actual == option {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("must be one of [%s]", strings.Join(options, ", ")), nil
+}
@@ -0,0 +1,155 @@
+package errors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip,omitempty" validate:"omitempty,len=5"`
+}
+
+type testPet struct {
+	Name string `json:"name" validate:"required,max=8"`
+	Kind string `json:"kind" validate:"oneof=cat dog"`
+}
+
+type testMeta struct {
+	Source string `validate:"required"`
+	Level  int    `json:"level" validate:"oneof=0 1 2"`
+}
+
+type testOwner struct {
+	testMeta
+	Name     string                 `json:"name" validate:"required,min=2"`
+	Email    string                 `json:"email" validate:"required,email"`
+	Homepage *string                `json:"homepage,omitempty" validate:"url"`
+	Age      int                    `json:"age" validate:"min=18,max=150"`
+	Address  *testAddress           `json:"address" validate:"required"`
+	Billing  testAddress            `json:"billing" validate:"omitempty"`
+	Pets     []testPet              `json:"pets" validate:"min=1"`
+	Friends  map[string]*testPet    `json:"friends/best"`
+	Tags     []string               `json:"-" validate:"max=2"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+	private  string                 `validate:"required"`
+}
+
+func TestValidate(t *testing.T) {
+	homepage := "not a url"
+	owner := testOwner{
+		Name:     "A",
+		Email:    "tom <tom@example.com>",
+		Homepage: &homepage,
+		Age:      12,
+		Address:  &testAddress{Zip: "123"},
+		Pets:     []testPet{{Name: "Tom", Kind: "cat"}, {Name: "Garfield!", Kind: "fish"}},
+		testMeta: testMeta{Level: 3},
+		Friends:  map[string]*testPet{"b": {Kind: "dog"}, "a": nil},
+		Tags:     []string{"a", "b", "c"},
+	}
+
+	err := Validate(&owner)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, InvalidArgument, Code(err))
+
+	expected := []FieldViolation{
+		{Field: "Source", Description: "is required"},
+		{Field: "level", Description: "must be one of [0, 1, 2]"},
+		{Field: "name", Description: "must be at least 2 characters long"},
+		{Field: "email", Description: "must be a valid email address"},
+		{Field: "homepage", Description: "must be a valid URL"},
+		{Field: "age", Description: "must be at least 18"},
+		{Field: "address.city", Description: "is required"},
+		{Field: "address.zip", Description: "must be exactly 5 characters long"},
+		{Field: "pets[1].name", Description: "must be at most 8 characters long"},
+		{Field: "pets[1].kind", Description: "must be one of [cat, dog]"},
+		{Field: "friends/best[b].name", Description: "is required"},
+		{Field: "Tags", Description: "must contain at most 2 items"},
+	}
+	assert.Equal(t, []Any{BadRequest{FieldViolations: expected}}, Details(err))
+
+	v := Validator{Path: PathPointer}
+	err = v.Validate(owner)
+	if assert.Error(t, err) {
+		var fields []string
+		for _, fv := range Details(err)[0].(BadRequest).FieldViolations {
+			fields = append(fields, fv.Field)
+		}
+		assert.Equal(t, []string{
+			"/Source", "/level", "/name", "/email", "/homepage", "/age", "/address/city", "/address/zip",
+			"/pets/1/name", "/pets/1/kind", "/friends~1best/b/name", "/Tags",
+		}, fields)
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	zip := testAddress{City: "Paris", Zip: "75001"}
+	owner := testOwner{
+		testMeta: testMeta{Source: "web"},
+		Name:     "Tom",
+		Email:    "tom@example.com",
+		Age:      30,
+		Address:  &zip,
+		Pets:     []testPet{{Name: "Tom", Kind: "cat"}},
+	}
+	assert.NoError(t, Validate(owner))
+	assert.NoError(t, Validate(nil))
+
+	owner.Pets = nil
+	err := Validate(&owner)
+	assert.Equal(t, []Any{BadRequest{FieldViolations: []FieldViolation{
+		{Field: "pets", Description: "must contain at least 1 items"},
+	}}}, Details(err))
+}
+
+func TestValidateCycle(t *testing.T) {
+	type node struct {
+		Name string `json:"name" validate:"required"`
+		Next *node  `json:"next"`
+	}
+	n := &node{}
+	n.Next = n
+
+	err := Validate(n)
+	assert.Equal(t, []Any{BadRequest{FieldViolations: []FieldViolation{
+		{Field: "name", Description: "is required"},
+	}}}, Details(err))
+}
+
+func TestValidateRules(t *testing.T) {
+	type request struct {
+		Slug string `json:"slug" check:"lower"`
+		Size string `json:"size" check:"min=x"`
+	}
+
+	v := Validator{
+		Tag: "check",
+		Rules: map[string]ValidationRule{
+			"lower": func(field reflect.Value, _ string) (string, error) {
+				if s := field.String(); s != strings.ToLower(s) {
+					return "must be lower case", nil
+				}
+				return "", nil
+			},
+		},
+	}
+
+	err := v.Validate(request{Slug: "Cat"})
+	assert.Equal(t, Internal, Code(err))
+	assert.EqualError(t, err, `validate: size: rule "min": strconv.ParseFloat: parsing "x": invalid syntax`)
+
+	err = v.Validate(struct {
+		Slug string `json:"slug" check:"lower,unknown"`
+	}{Slug: "cat"})
+	assert.EqualError(t, err, `validate: slug: unknown rule "unknown"`)
+
+	err = v.Validate(struct {
+		Slug string `json:"slug" check:"lower"`
+	}{Slug: "Cat"})
+	assert.Equal(t, InvalidArgument, Code(err))
+	assert.Equal(t, "must be lower case", Details(err)[0].(BadRequest).FieldViolations[0].Description)
+}